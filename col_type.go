@@ -0,0 +1,119 @@
+package sqlm
+
+import (
+	"database/sql"
+	"reflect"
+	"time"
+)
+
+// DBTyper is implemented by the custom column types to declare their column types for drivers,
+// it's used when the type is not set in struct tag.
+type DBTyper interface {
+	// DBType return the column type for the driver, empty means inferring from the go type.
+	DBType(driver string) string
+}
+
+// colKind kind of go types for inferring column types.
+type colKind int
+
+const (
+	colKindUnknown colKind = iota
+	colKindBool
+	colKindInt8
+	colKindInt16
+	colKindInt32
+	colKindInt64
+	colKindUint8
+	colKindUint16
+	colKindUint32
+	colKindUint64
+	colKindFloat32
+	colKindFloat64
+	colKindString
+	colKindBytes
+	colKindTime
+	colKindJSON
+)
+
+var (
+	dbTyperType = reflect.TypeOf((*DBTyper)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+	bytesType   = reflect.TypeOf([]byte(nil))
+
+	// kinds of the nullable types in database/sql.
+	sqlNullKinds = map[reflect.Type]colKind{
+		reflect.TypeOf(sql.NullBool{}):    colKindBool,
+		reflect.TypeOf(sql.NullInt32{}):   colKindInt32,
+		reflect.TypeOf(sql.NullInt64{}):   colKindInt64,
+		reflect.TypeOf(sql.NullFloat64{}): colKindFloat64,
+		reflect.TypeOf(sql.NullString{}):  colKindString,
+		reflect.TypeOf(sql.NullTime{}):    colKindTime,
+	}
+)
+
+// inferColType return the column type for driver by the go type of column,
+// types declares the column types of kinds in the dialect.
+func inferColType(c *ColSchema, driver string, types map[colKind]string) string {
+	if c.Type != "" || c.goType == nil {
+		return c.Type
+	}
+
+	t := c.goType
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if reflect.PtrTo(t).Implements(dbTyperType) {
+		if ret := reflect.New(t).Interface().(DBTyper).DBType(driver); ret != "" {
+			return ret
+		}
+	}
+
+	return types[goColKind(t)]
+}
+
+// goColKind return kind of the go type for inferring column type.
+func goColKind(t reflect.Type) colKind {
+	if kind, ok := sqlNullKinds[t]; ok {
+		return kind
+	}
+
+	switch {
+	case t == timeType || t.ConvertibleTo(timeType):
+		return colKindTime
+	case t == bytesType || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8):
+		return colKindBytes
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return colKindBool
+	case reflect.Int8:
+		return colKindInt8
+	case reflect.Int16:
+		return colKindInt16
+	case reflect.Int32:
+		return colKindInt32
+	case reflect.Int, reflect.Int64:
+		return colKindInt64
+	case reflect.Uint8:
+		return colKindUint8
+	case reflect.Uint16:
+		return colKindUint16
+	case reflect.Uint32:
+		return colKindUint32
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return colKindUint64
+	case reflect.Float32:
+		return colKindFloat32
+	case reflect.Float64:
+		return colKindFloat64
+	case reflect.String:
+		return colKindString
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		// 如 StringList, HashCol 等以 json 存储
+		return colKindJSON
+	default:
+		return colKindUnknown
+	}
+}
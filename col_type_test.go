@@ -0,0 +1,126 @@
+package sqlm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testPoint custom column type declaring its column types.
+type testPoint struct {
+	X, Y float64
+}
+
+// Scan for interface sql.Scanner
+func (p *testPoint) Scan(val interface{}) error {
+	return JSONColScan(val, p)
+}
+
+// Value for interface driver.Valuer
+func (p testPoint) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (*testPoint) DBType(driver string) string {
+	if driver == DriverMysql {
+		return "POINT"
+	}
+	return ""
+}
+
+// testUntypedRecord record without column types in tags.
+type testUntypedRecord struct {
+	ID         int64          `db:"id,auto_increment"`
+	Enabled    bool           `db:"enabled"`
+	Level      int8           `db:"level"`
+	Count      int32          `db:"count"`
+	Size       uint64         `db:"size"`
+	Score      float64        `db:"score"`
+	Name       string         `db:"name,not_null"`
+	Remark     NullString     `db:"remark"`
+	Comment    sql.NullString `db:"comment"`
+	Total      *int64         `db:"total"`
+	Data       []byte         `db:"data"`
+	CreateTime time.Time      `db:"createTime"`
+	Tags       StringList     `db:"tags"`
+	Attrs      HashCol        `db:"attrs"`
+	Location   testPoint      `db:"location"`
+	Title      string         `db:"title,type=VARCHAR(64)"`
+}
+
+func TestDialect_ColumnType_inferred(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   []string
+	}{
+		{
+			DriverMysql,
+			[]string{
+				"BIGINT", "TINYINT(1)", "TINYINT", "INT", "BIGINT UNSIGNED", "DOUBLE", "VARCHAR(255)", "VARCHAR(255)",
+				"VARCHAR(255)", "BIGINT", "BLOB", "DATETIME", "JSON", "JSON", "POINT", "VARCHAR(64)",
+			},
+		},
+		{
+			DriverSQLite3,
+			[]string{
+				"INTEGER", "INTEGER", "INTEGER", "INTEGER", "INTEGER", "REAL", "TEXT", "TEXT",
+				"TEXT", "INTEGER", "BLOB", "DATETIME", "TEXT", "TEXT", "TEXT", "VARCHAR(64)",
+			},
+		},
+		{
+			DriverPostgres,
+			[]string{
+				"BIGINT", "BOOLEAN", "SMALLINT", "INTEGER", "NUMERIC(20)", "DOUBLE PRECISION", "VARCHAR(255)", "VARCHAR(255)",
+				"VARCHAR(255)", "BIGINT", "BYTEA", "TIMESTAMP", "JSONB", "JSONB", "JSONB", "VARCHAR(64)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			dialect, err := GetDialect(tt.driver)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s := newTestTableSchema(tt.driver, "test", testUntypedRecord{})
+			var got []string
+			for _, c := range s.Columns {
+				got = append(got, dialect.ColumnType(c))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Dialect.ColumnType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTable_untypedRecord(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_untyped", &testUntypedRecord{})
+
+	total := int64(3)
+	record := &testUntypedRecord{
+		ID:         1,
+		Enabled:    true,
+		Name:       "a",
+		Total:      &total,
+		Data:       []byte("data"),
+		CreateTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tags:       StringList{"x", "y"},
+		Attrs:      HashCol{"k": "v"},
+		Location:   testPoint{X: 1, Y: 2},
+	}
+	if _, err := table.Insert(record); err != nil {
+		t.Fatal(err)
+	}
+
+	var got testUntypedRecord
+	if err := table.Get(SelectorFilter{"id": 1}, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, record) {
+		t.Errorf("Table.Get() = %+v, want %+v", &got, record)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -12,6 +13,7 @@ import (
 //	dns format:
 //		mysql: 	 [username[:password]@][protocol[(address)]]/dbname[?param1=value1&...&paramN=valueN]
 //		sqlite3: file:test.db[?param1=value1&...&paramN=valueN]
+//		postgres: postgres://[username[:password]@]host[:port]/dbname[?param1=value1&...&paramN=valueN]
 type Database struct {
 	Driver string `json:"driver"`
 	DSN    string `json:"dsn"`
@@ -46,7 +48,7 @@ func (p *Database) SetCon(con *sql.DB) {
 }
 
 func (p *Database) newCon() (*sqlx.DB, error) {
-	db, err := sqlx.Open(p.Driver, p.DSN)
+	db, err := sqlx.Open(p.Driver, p.conDSN())
 	if err != nil {
 		return nil, fmt.Errorf("db connect failed: %w", err)
 	}
@@ -57,6 +59,25 @@ func (p *Database) newCon() (*sqlx.DB, error) {
 	return db, nil
 }
 
+// conDSN return the dsn for connecting, the foreign keys are enforced for sqlite connections
+// unless `_foreign_keys` or `_fk` is set in dsn.
+func (p *Database) conDSN() string {
+	if p.Driver != DriverSQLite && p.Driver != DriverSQLite3 {
+		return p.DSN
+	}
+
+	if strings.Contains(p.DSN, "_foreign_keys=") || strings.Contains(p.DSN, "_fk=") {
+		return p.DSN
+	}
+
+	sep := "?"
+	if strings.Contains(p.DSN, "?") {
+		sep = "&"
+	}
+
+	return p.DSN + sep + "_foreign_keys=1"
+}
+
 // Close db connection.
 func (p *Database) Close() error {
 	if p.dbCon == nil {
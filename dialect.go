@@ -0,0 +1,195 @@
+package sqlm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	// sql dialects for drivers.
+	dialectsMu sync.RWMutex
+	dialects   = map[string]Dialect{
+		DriverMysql:    new(MySQLDialect),
+		DriverSQLite:   new(SQLiteDialect),
+		DriverSQLite3:  new(SQLiteDialect),
+		DriverPostgres: new(PostgresDialect),
+	}
+)
+
+// identifierRegex match plain identifier or the one qualified by table name, such as: `col` and `t.col`.
+var identifierRegex = regexp.MustCompile(`^\w+(\.\w+)?$`)
+
+// Dialect is the interface that groups the sql differences between databases.
+type Dialect interface {
+	// ColumnDDL return column definition in table creating statement.
+	ColumnDDL(c *ColSchema, onlyOnePrimaryCol bool) string
+	// ColumnType return the column type in the dialect.
+	ColumnType(c *ColSchema) string
+	// AutoIncrement deal with the auto increment column, return the adjusted copy of column and the final primary columns,
+	// c is passed by value so the schema shared by tables is not changed.
+	AutoIncrement(c ColSchema, primaryCols []string) (ColSchema, []string, error)
+	// IndexDDL return index definition, inline is true when it should be declared in table creating statement.
+	IndexDDL(table, name string, cols []string, unique bool) (ddl string, inline bool)
+	// Quote quote identifier, such as table name and column name.
+	Quote(identifier string) string
+	// BindType return placeholder style, it is one of sqlx bind types: sqlx.QUESTION, sqlx.DOLLAR...
+	BindType() int
+	// JSONExtract return expression for extracting value from json column by path, path likes: `a.b`.
+	JSONExtract(col, path string) string
+	// InsertReturning return clause for inserting statement to return the auto increment column value,
+	// empty means the driver supports LastInsertId.
+	InsertReturning(col string) string
+	// Upsert return inserting statement into table which updates the exist record when conflicted,
+	// the updateCols are set to the inserting values and the increaseCols are increased by one.
+	Upsert(table, insert string, conflictCols, updateCols, increaseCols []string) (string, error)
+	// LimitOffset return clause for limit and offset, zero value means no limit or no offset.
+	LimitOffset(limit, offset int64) string
+	// IsTableNotExist report whether the error is caused by table not existed.
+	IsTableNotExist(err error) bool
+	// DDLCommitsTx report whether DDL statements commit the transaction implicitly.
+	DDLCommitsTx() bool
+	// ErrorAbortsTx report whether the transaction is aborted after any statement failed in it.
+	ErrorAbortsTx() bool
+}
+
+// BatchDialect is the optional interface of Dialect for the statements with many rows or bound variables,
+// records are inserted one by one when the dialect does not implement it.
+type BatchDialect interface {
+	// MaxBindVars return the maximum number of bound variables in one statement, zero means no limit.
+	MaxBindVars() int
+	// MaxPacketSize return the maximum size in bytes of one statement with its arguments, zero means no limit.
+	MaxPacketSize() int
+	// BatchInsertIDs return ids of the rows inserted by one multi-row statement from its LastInsertId,
+	// nil means they can not be inferred. It's called only when the table has an auto increment column.
+	BatchInsertIDs(lastInsertID int64, rows int) []int64
+}
+
+// InspectDialect is the optional interface of Dialect for reading and altering the live schema,
+// it's required by Table#Migrate().
+type InspectDialect interface {
+	// TableColumns return the name, type and nullability of columns of the live table, empty when table not exists.
+	TableColumns(ctx context.Context, q sqlx.QueryerContext, table string) ([]*ColSchema, error)
+	// TableIndexes return the columns of indexes of the live table, including the primary and unique keys.
+	TableIndexes(ctx context.Context, q sqlx.QueryerContext, table string) ([][]string, error)
+	// ModifyColumnDDL return statements for changing the type and nullability of column in table.
+	ModifyColumnDDL(table string, c *ColSchema) ([]string, error)
+}
+
+// TableListDialect is the optional interface of Dialect for listing the live tables,
+// it's required by Table#ShardTables() and the queries across shards.
+type TableListDialect interface {
+	// TableNames return names of the live tables in current database or schema, ordered by name.
+	TableNames(ctx context.Context, q sqlx.QueryerContext) ([]string, error)
+}
+
+// MergeDialect is the optional interface of Dialect for merging the ordered records of shards,
+// the strings are compared by bytes in merging, so ordering by string columns across shards
+// is rejected unless the dialect reports so.
+type MergeDialect interface {
+	// BinaryStringOrder report whether strings are ordered by bytes in the default collation.
+	BinaryStringOrder() bool
+}
+
+// DupKeyDialect is the optional interface of Dialect for recognizing the errors of duplicate keys,
+// it's used by Migrator for waiting the lock held by others.
+type DupKeyDialect interface {
+	// IsDupKey report whether the error is caused by inserting duplicate primary or unique keys.
+	IsDupKey(err error) bool
+}
+
+// RegisterDialect register sql dialect for given driver.
+func RegisterDialect(name string, dialect Dialect) {
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+
+	if dialect == nil {
+		panic("sqlm: RegisterDialect dialect is nil")
+	}
+
+	if _, dup := dialects[name]; dup {
+		panic("sqlm: RegisterDialect called twice for driver " + name)
+	}
+
+	dialects[name] = dialect
+}
+
+// UnRegisterDialect uninstall sql dialect for given driver.
+func UnRegisterDialect(driver string) {
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+
+	delete(dialects, driver)
+}
+
+// Dialects returns a sorted list of the driver names of the registered dialects.
+func Dialects() []string {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+
+	var list []string
+	for name := range dialects {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+
+	return list
+}
+
+// GetDialect return the registered sql dialect for given driver.
+func GetDialect(driver string) (Dialect, error) {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+
+	dialect, ok := dialects[driver]
+	if !ok {
+		return nil, fmt.Errorf("not support driver: %s", driver)
+	}
+
+	return dialect, nil
+}
+
+// quoteIdentifier quote plain identifier by dialect, the table qualified identifier will be quoted by parts,
+// expressions such as `count(*)` are returned directly, and so do all when dialect is nil.
+func quoteIdentifier(d Dialect, identifier string) string {
+	if d == nil || !identifierRegex.MatchString(identifier) {
+		return identifier
+	}
+
+	parts := strings.Split(identifier, ".")
+	for i, p := range parts {
+		parts[i] = d.Quote(p)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// quoteTableName quote table name by dialect, it's always quoted because the split table name may contain
+// the characters of split values such as `-`. The name qualified by schema is quoted in parts.
+func quoteTableName(d Dialect, table string) string {
+	if d == nil {
+		return table
+	}
+
+	parts := strings.Split(table, ".")
+	for i, p := range parts {
+		parts[i] = d.Quote(p)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// quoteIdentifiers quote identifiers by dialect.
+func quoteIdentifiers(d Dialect, identifiers []string) []string {
+	ret := make([]string, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		ret = append(ret, quoteIdentifier(d, identifier))
+	}
+
+	return ret
+}
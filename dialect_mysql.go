@@ -0,0 +1,240 @@
+package sqlm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// mysqlColTypes column types inferred from go types when the type is not set.
+var mysqlColTypes = map[colKind]string{
+	colKindBool:    "TINYINT(1)",
+	colKindInt8:    "TINYINT",
+	colKindInt16:   "SMALLINT",
+	colKindInt32:   "INT",
+	colKindInt64:   "BIGINT",
+	colKindUint8:   "TINYINT UNSIGNED",
+	colKindUint16:  "SMALLINT UNSIGNED",
+	colKindUint32:  "INT UNSIGNED",
+	colKindUint64:  "BIGINT UNSIGNED",
+	colKindFloat32: "FLOAT",
+	colKindFloat64: "DOUBLE",
+	colKindString:  "VARCHAR(255)",
+	colKindBytes:   "BLOB",
+	colKindTime:    DateTimeType,
+	colKindJSON:    "JSON",
+}
+
+// mysqlMaxLimit is the max rows limit for mysql, it's required when offset setted.
+const mysqlMaxLimit = "18446744073709551615"
+
+var mysqlDupKeyRegex = regexp.MustCompile(`Error\s+1062|Duplicate\s+entry`)
+
+var mysqlTableNotExistRegex = regexp.MustCompile(`[tT]able\s+.+\s+doesn't\s+exist`)
+
+// MySQLDialect the sql dialect of mysql, it can be embedded by the variants such as TiDB.
+type MySQLDialect struct {
+	// ConsecutiveInsertIDs set it when the ids of rows in one multi-row insert are consecutive,
+	// it requires `innodb_autoinc_lock_mode` 0 or 1 and `auto_increment_increment` 1 on server.
+	ConsecutiveInsertIDs bool
+}
+
+var (
+	_ BatchDialect     = (*MySQLDialect)(nil)
+	_ InspectDialect   = (*MySQLDialect)(nil)
+	_ TableListDialect = (*MySQLDialect)(nil)
+	_ DupKeyDialect    = (*MySQLDialect)(nil)
+)
+
+func (d *MySQLDialect) ColumnDDL(c *ColSchema, onlyOnePrimaryCol bool) string {
+	line := fmt.Sprintf("%s %s", d.Quote(c.Name), d.ColumnType(c))
+
+	if c.NotNull || c.AutoIncrement {
+		line += fmt.Sprintf(" %s", AttrNotNullMySQL)
+	}
+	if c.Default && c.DefaultStr != "" {
+		line += fmt.Sprintf(" %s %s", AttrDefaultMySQL, c.DefaultStr)
+	}
+	if c.AutoUpdate && c.AutoUpdateStr != "" {
+		line += fmt.Sprintf(" %s %s", AttrOnUpdateMySQL, c.AutoUpdateStr)
+	}
+	if onlyOnePrimaryCol && c.Primary {
+		line += fmt.Sprintf(" %s", attrPrimaryKey)
+	}
+	if c.Unique {
+		line += fmt.Sprintf(" %s", attrUniqueKeyMySQL)
+	}
+	if c.AutoIncrement {
+		line += " AUTO_INCREMENT"
+	}
+
+	return line
+}
+
+func (*MySQLDialect) ColumnType(c *ColSchema) string {
+	return inferColType(c, DriverMysql, mysqlColTypes)
+}
+
+// AutoIncrement set the auto increment column as primary key when none explicit primary keys.
+func (*MySQLDialect) AutoIncrement(c ColSchema, primaryCols []string) (ColSchema, []string, error) {
+	if len(primaryCols) > 0 {
+		return c, primaryCols, nil
+	}
+
+	c.Primary = true
+	c.setKeyAttrs()
+
+	return c, []string{c.Name}, nil
+}
+
+func (d *MySQLDialect) IndexDDL(_, name string, cols []string, unique bool) (string, bool) {
+	key := attrKey
+	if unique {
+		key = attrUniqueKeyMySQL
+	}
+
+	return fmt.Sprintf("%s %s (%s)", key, d.Quote(name), strings.Join(quoteIdentifiers(d, cols), ",")), true
+}
+
+func (*MySQLDialect) Quote(identifier string) string {
+	return "`" + strings.Replace(identifier, "`", "``", -1) + "`"
+}
+
+func (*MySQLDialect) BindType() int {
+	return sqlx.QUESTION
+}
+
+func (*MySQLDialect) JSONExtract(col, path string) string {
+	return fmt.Sprintf(`JSON_EXTRACT(%s, "$.%s")`, col, path)
+}
+
+func (*MySQLDialect) InsertReturning(string) string {
+	return ""
+}
+
+// Upsert the conflicted row is kept by updating the key to itself when no columns to update,
+// `INSERT IGNORE` is not used because it ignores the other errors too, such as not null and truncation.
+func (d *MySQLDialect) Upsert(_, insert string, conflictCols, updateCols, increaseCols []string) (string, error) {
+	if len(updateCols) == 0 {
+		if len(conflictCols) == 0 {
+			return "", &ErrorSQLInvalid{Message: "table schema should has primary or unique col setted for upsert"}
+		}
+		k := d.Quote(conflictCols[0])
+		return fmt.Sprintf("%s ON DUPLICATE KEY UPDATE %s=%s", insert, k, k), nil
+	}
+
+	var updatePatterns []string
+	for _, k := range updateCols {
+		updatePatterns = append(updatePatterns, fmt.Sprintf("%s=:%s", d.Quote(k), k))
+	}
+	for _, k := range increaseCols {
+		updatePatterns = append(updatePatterns, fmt.Sprintf("%s=%s+1", d.Quote(k), d.Quote(k)))
+	}
+
+	return insert + " ON DUPLICATE KEY UPDATE " + strings.Join(updatePatterns, ","), nil
+}
+
+// MaxBindVars the placeholders count of prepared statement is limited to 65535.
+func (*MySQLDialect) MaxBindVars() int {
+	return 65535
+}
+
+// MaxPacketSize the default `max_allowed_packet` of mysql 5.7.
+func (*MySQLDialect) MaxPacketSize() int {
+	return 4 << 20
+}
+
+// BatchInsertIDs LastInsertId is the id of the first row, the ids are inferred only when
+// ConsecutiveInsertIDs is set, because they may be interleaved or stepped by the server settings.
+func (d *MySQLDialect) BatchInsertIDs(lastInsertID int64, rows int) []int64 {
+	if !d.ConsecutiveInsertIDs {
+		return nil
+	}
+
+	ids := make([]int64, 0, rows)
+	for i := 0; i < rows; i++ {
+		ids = append(ids, lastInsertID+int64(i))
+	}
+
+	return ids
+}
+
+func (*MySQLDialect) LimitOffset(limit, offset int64) string {
+	switch {
+	case limit > 0 && offset > 0:
+		return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+	case limit > 0:
+		return fmt.Sprintf("LIMIT %d", limit)
+	case offset > 0:
+		return fmt.Sprintf("LIMIT %s OFFSET %d", mysqlMaxLimit, offset)
+	default:
+		return ""
+	}
+}
+
+func (*MySQLDialect) TableColumns(ctx context.Context, q sqlx.QueryerContext, table string) ([]*ColSchema, error) {
+	query := "SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_KEY FROM information_schema.COLUMNS " +
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION"
+	rows, err := q.QueryxContext(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ret []*ColSchema
+	for rows.Next() {
+		var name, colType, nullable, key string
+		if err := rows.Scan(&name, &colType, &nullable, &key); err != nil {
+			return nil, err
+		}
+		ret = append(ret, &ColSchema{Name: name, Type: colType, NotNull: nullable == "NO", Primary: key == "PRI"})
+	}
+
+	return ret, rows.Err()
+}
+
+func (*MySQLDialect) TableIndexes(ctx context.Context, q sqlx.QueryerContext, table string) ([][]string, error) {
+	query := "SELECT INDEX_NAME, COLUMN_NAME FROM information_schema.STATISTICS " +
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY INDEX_NAME, SEQ_IN_INDEX"
+	rows, err := q.QueryxContext(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanIndexCols(rows)
+}
+
+func (*MySQLDialect) TableNames(ctx context.Context, q sqlx.QueryerContext) ([]string, error) {
+	query := "SELECT TABLE_NAME FROM information_schema.TABLES " +
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME"
+
+	return scanTableNames(ctx, q, query)
+}
+
+// ModifyColumnDDL redefine the column, the keys on it are not changed.
+func (d *MySQLDialect) ModifyColumnDDL(table string, c *ColSchema) ([]string, error) {
+	col := *c
+	col.Unique = false
+
+	return []string{fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", quoteTableName(d, table), d.ColumnDDL(&col, false))}, nil
+}
+
+func (*MySQLDialect) IsTableNotExist(err error) bool {
+	return err != nil && mysqlTableNotExistRegex.MatchString(err.Error())
+}
+
+func (*MySQLDialect) IsDupKey(err error) bool {
+	return err != nil && mysqlDupKeyRegex.MatchString(err.Error())
+}
+
+func (*MySQLDialect) DDLCommitsTx() bool {
+	return true
+}
+
+func (*MySQLDialect) ErrorAbortsTx() bool {
+	return false
+}
@@ -0,0 +1,257 @@
+package sqlm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// postgresColTypes column types inferred from go types when the type is not set.
+var postgresColTypes = map[colKind]string{
+	colKindBool:    "BOOLEAN",
+	colKindInt8:    "SMALLINT",
+	colKindInt16:   "SMALLINT",
+	colKindInt32:   "INTEGER",
+	colKindInt64:   "BIGINT",
+	colKindUint8:   "SMALLINT",
+	colKindUint16:  "INTEGER",
+	colKindUint32:  "BIGINT",
+	colKindUint64:  "NUMERIC(20)",
+	colKindFloat32: "REAL",
+	colKindFloat64: "DOUBLE PRECISION",
+	colKindString:  "VARCHAR(255)",
+	colKindBytes:   "BYTEA",
+	colKindTime:    "TIMESTAMP",
+	colKindJSON:    "JSONB",
+}
+
+var postgresDupKeyRegex = regexp.MustCompile(`duplicate\s+key\s+value\s+violates`)
+
+var postgresTableNotExistRegex = regexp.MustCompile(`relation\s+.+\s+does\s+not\s+exist`)
+
+// PostgresDialect the sql dialect of postgresql, it can be embedded by the compatible databases.
+type PostgresDialect struct{}
+
+var (
+	_ BatchDialect     = (*PostgresDialect)(nil)
+	_ InspectDialect   = (*PostgresDialect)(nil)
+	_ TableListDialect = (*PostgresDialect)(nil)
+	_ DupKeyDialect    = (*PostgresDialect)(nil)
+)
+
+func (d *PostgresDialect) ColumnDDL(c *ColSchema, onlyOnePrimaryCol bool) string {
+	line := fmt.Sprintf("%s %s", d.Quote(c.Name), d.ColumnType(c))
+
+	if c.NotNull || c.AutoIncrement {
+		line += fmt.Sprintf(" %s", AttrNotNullPostgres)
+	}
+	if c.Default && c.DefaultStr != "" {
+		line += fmt.Sprintf(" %s %s", AttrDefaultPostgres, c.DefaultStr)
+	}
+	if c.AutoIncrement {
+		line += " GENERATED BY DEFAULT AS IDENTITY"
+	}
+	if onlyOnePrimaryCol && c.Primary {
+		line += fmt.Sprintf(" %s", attrPrimaryKey)
+	}
+	if c.Unique {
+		line += fmt.Sprintf(" %s", attrUniqueKeyPostgres)
+	}
+
+	return line
+}
+
+func (*PostgresDialect) ColumnType(c *ColSchema) string {
+	if c.Type == "" {
+		return inferColType(c, DriverPostgres, postgresColTypes)
+	}
+
+	return postgresColType(c.Type)
+}
+
+// AutoIncrement set the auto increment column as primary key when none explicit primary keys.
+func (*PostgresDialect) AutoIncrement(c ColSchema, primaryCols []string) (ColSchema, []string, error) {
+	return new(MySQLDialect).AutoIncrement(c, primaryCols)
+}
+
+// IndexDDL return the index creating statement, index name is prefixed with table name
+// because it's unique in schema, the index is created in the schema of table.
+func (d *PostgresDialect) IndexDDL(table, name string, cols []string, unique bool) (string, bool) {
+	_, bareTable := splitTableName(table)
+	return indexCreateSQL(d, d.Quote(bareTable+"_"+name), quoteTableName(d, table), cols, unique), false
+}
+
+func (*PostgresDialect) Quote(identifier string) string {
+	return `"` + strings.Replace(identifier, `"`, `""`, -1) + `"`
+}
+
+func (*PostgresDialect) BindType() int {
+	return sqlx.DOLLAR
+}
+
+// JSONExtract return text value in json column by path.
+func (*PostgresDialect) JSONExtract(col, path string) string {
+	return fmt.Sprintf(`CAST(%s AS JSONB) #>> '{%s}'`, col, strings.Replace(path, ".", ",", -1))
+}
+
+func (d *PostgresDialect) InsertReturning(col string) string {
+	return "RETURNING " + d.Quote(col)
+}
+
+func (d *PostgresDialect) Upsert(table, insert string, conflictCols, updateCols, increaseCols []string) (string, error) {
+	return onConflictUpsert(d, table, insert, conflictCols, updateCols, increaseCols)
+}
+
+// MaxBindVars the parameters count of one statement is limited to 65535 by the wire protocol.
+func (*PostgresDialect) MaxBindVars() int {
+	return 65535
+}
+
+func (*PostgresDialect) MaxPacketSize() int {
+	return 0
+}
+
+// BatchInsertIDs LastInsertId is not supported, ids are returned by `RETURNING` clause.
+func (*PostgresDialect) BatchInsertIDs(int64, int) []int64 {
+	return nil
+}
+
+func (*PostgresDialect) LimitOffset(limit, offset int64) string {
+	var parts []string
+	if limit > 0 {
+		parts = append(parts, fmt.Sprintf("LIMIT %d", limit))
+	}
+	if offset > 0 {
+		parts = append(parts, fmt.Sprintf("OFFSET %d", offset))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// TableColumns the types are returned in the style of column definition, such as: `VARCHAR(32)`, `TIMESTAMP`.
+func (*PostgresDialect) TableColumns(ctx context.Context, q sqlx.QueryerContext, table string) ([]*ColSchema, error) {
+	query := "SELECT column_name, data_type, character_maximum_length, numeric_precision, numeric_scale, is_nullable " +
+		"FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1 ORDER BY ordinal_position"
+	rows, err := q.QueryxContext(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ret []*ColSchema
+	for rows.Next() {
+		var name, dataType, nullable string
+		var maxLength, precision, scale sql.NullInt64
+		if err := rows.Scan(&name, &dataType, &maxLength, &precision, &scale, &nullable); err != nil {
+			return nil, err
+		}
+
+		colType := strings.ToUpper(dataType)
+		if v, ok := postgresDataTypes[colType]; ok {
+			colType = v
+		}
+		if maxLength.Valid {
+			colType = fmt.Sprintf("%s(%d)", colType, maxLength.Int64)
+		}
+		// precision of integer and float types is also set, it's only declared for numeric.
+		if colType == "NUMERIC" && precision.Valid {
+			colType = fmt.Sprintf("%s(%d,%d)", colType, precision.Int64, scale.Int64)
+		}
+		ret = append(ret, &ColSchema{Name: name, Type: colType, NotNull: nullable == "NO"})
+	}
+
+	return ret, rows.Err()
+}
+
+func (*PostgresDialect) TableIndexes(ctx context.Context, q sqlx.QueryerContext, table string) ([][]string, error) {
+	query := "SELECT i.relname, a.attname FROM pg_index x " +
+		"JOIN pg_class c ON c.oid = x.indrelid " +
+		"JOIN pg_class i ON i.oid = x.indexrelid " +
+		"JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum = ANY(x.indkey) " +
+		"WHERE c.relname = $1 AND c.relnamespace = current_schema()::regnamespace " +
+		"ORDER BY i.relname, array_position(x.indkey::int2[], a.attnum)"
+	rows, err := q.QueryxContext(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanIndexCols(rows)
+}
+
+func (*PostgresDialect) TableNames(ctx context.Context, q sqlx.QueryerContext) ([]string, error) {
+	query := "SELECT table_name FROM information_schema.tables " +
+		"WHERE table_schema = current_schema() AND table_type = 'BASE TABLE' ORDER BY table_name"
+
+	return scanTableNames(ctx, q, query)
+}
+
+// ModifyColumnDDL the values are cast to the new type explicitly, such as from VARCHAR to INTEGER,
+// the statement fails when any value can not be cast.
+func (d *PostgresDialect) ModifyColumnDDL(table string, c *ColSchema) ([]string, error) {
+	alter := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", quoteTableName(d, table), d.Quote(c.Name))
+	nullability := "DROP NOT NULL"
+	if c.NotNull || c.AutoIncrement {
+		nullability = "SET NOT NULL"
+	}
+
+	colType := d.ColumnType(c)
+	return []string{
+		fmt.Sprintf("%s TYPE %s USING %s::%s", alter, colType, d.Quote(c.Name), colType),
+		fmt.Sprintf("%s %s", alter, nullability),
+	}, nil
+}
+
+func (*PostgresDialect) IsTableNotExist(err error) bool {
+	return err != nil && postgresTableNotExistRegex.MatchString(err.Error())
+}
+
+func (*PostgresDialect) IsDupKey(err error) bool {
+	return err != nil && postgresDupKeyRegex.MatchString(err.Error())
+}
+
+func (*PostgresDialect) DDLCommitsTx() bool {
+	return false
+}
+
+func (*PostgresDialect) ErrorAbortsTx() bool {
+	return true
+}
+
+// postgresDataTypes the column types for the `data_type` of information_schema.
+var postgresDataTypes = map[string]string{
+	"CHARACTER VARYING":           "VARCHAR",
+	"CHARACTER":                   "CHAR",
+	"TIMESTAMP WITHOUT TIME ZONE": "TIMESTAMP",
+	"TIMESTAMP WITH TIME ZONE":    "TIMESTAMPTZ",
+}
+
+// postgresColType translate the mysql style column types to postgresql types.
+func postgresColType(colType string) string {
+	m := map[string]string{
+		"DATETIME":   "TIMESTAMP",
+		"TINYINT":    "SMALLINT",
+		"INT":        "INTEGER",
+		"DOUBLE":     "DOUBLE PRECISION",
+		"BLOB":       "BYTEA",
+		"LONGBLOB":   "BYTEA",
+		"MEDIUMTEXT": "TEXT",
+		"LONGTEXT":   "TEXT",
+	}
+
+	// display width is not supported, such as: `TINYINT(4)`.
+	baseType := strings.ToUpper(strings.TrimSpace(colType))
+	if i := strings.Index(baseType, "("); i > 0 {
+		baseType = baseType[:i]
+	}
+
+	if v, ok := m[baseType]; ok {
+		return v
+	}
+
+	return colType
+}
@@ -0,0 +1,324 @@
+package sqlm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// sqliteColTypes column types inferred from go types when the type is not set.
+var sqliteColTypes = map[colKind]string{
+	colKindBool:    "INTEGER",
+	colKindInt8:    "INTEGER",
+	colKindInt16:   "INTEGER",
+	colKindInt32:   "INTEGER",
+	colKindInt64:   "INTEGER",
+	colKindUint8:   "INTEGER",
+	colKindUint16:  "INTEGER",
+	colKindUint32:  "INTEGER",
+	colKindUint64:  "INTEGER",
+	colKindFloat32: "REAL",
+	colKindFloat64: "REAL",
+	colKindString:  "TEXT",
+	colKindBytes:   "BLOB",
+	colKindTime:    DateTimeType,
+	colKindJSON:    "TEXT",
+}
+
+var sqliteDupKeyRegex = regexp.MustCompile(`UNIQUE\s+constraint\s+failed|PRIMARY\s+KEY\s+must\s+be\s+unique`)
+
+var sqliteTableNotExistRegex = regexp.MustCompile(`no\s+such\s+table`)
+
+// SQLiteDialect the sql dialect of sqlite v3, it can be embedded by the compatible databases.
+type SQLiteDialect struct{}
+
+var (
+	_ BatchDialect     = (*SQLiteDialect)(nil)
+	_ InspectDialect   = (*SQLiteDialect)(nil)
+	_ TableListDialect = (*SQLiteDialect)(nil)
+	_ DupKeyDialect    = (*SQLiteDialect)(nil)
+	_ MergeDialect     = (*SQLiteDialect)(nil)
+)
+
+func (d *SQLiteDialect) ColumnDDL(c *ColSchema, onlyOnePrimaryCol bool) string {
+	line := fmt.Sprintf("%s %s", d.Quote(c.Name), d.ColumnType(c))
+	if c.NotNull {
+		line += fmt.Sprintf(" %s", AttrNotNullSQLite)
+	}
+	if c.Default && c.DefaultStr != "" {
+		line += fmt.Sprintf(" %s %s", AttrDefaultSQLite, c.DefaultStr)
+	}
+	if onlyOnePrimaryCol && c.Primary {
+		line += fmt.Sprintf(" %s", attrPrimaryKey)
+	}
+	if c.Unique {
+		line += fmt.Sprintf(" %s", attrUniqueKeySQLite)
+	}
+
+	return line
+}
+
+func (*SQLiteDialect) ColumnType(c *ColSchema) string {
+	return inferColType(c, DriverSQLite3, sqliteColTypes)
+}
+
+// AutoIncrement turn the auto increment column to be `INTEGER PRIMARY KEY` which is the alias of rowid.
+func (*SQLiteDialect) AutoIncrement(c ColSchema, primaryCols []string) (ColSchema, []string, error) {
+	if len(primaryCols) > 0 {
+		return c, nil, errors.New("sqlite not support both auto increment and other primary columns at same time")
+	}
+
+	c.AutoIncrement = false
+	c.Primary = true
+	c.Type = "INTEGER"
+	c.setKeyAttrs()
+	c.NotNull = false
+
+	return c, []string{c.Name}, nil
+}
+
+// IndexDDL return the index creating statement, index name is prefixed with table name
+// because it's unique in database. The schema of qualified table is set on the index name,
+// the table name in `ON` clause can not be qualified in sqlite.
+func (d *SQLiteDialect) IndexDDL(table, name string, cols []string, unique bool) (string, bool) {
+	schema, bareTable := splitTableName(table)
+	if schema == "" {
+		return indexCreateSQL(d, d.Quote(table+"_"+name), d.Quote(table), cols, unique), false
+	}
+
+	return indexCreateSQL(d, quoteTableName(d, schema+"."+bareTable+"_"+name), d.Quote(bareTable), cols, unique), false
+}
+
+func (*SQLiteDialect) Quote(identifier string) string {
+	return `"` + strings.Replace(identifier, `"`, `""`, -1) + `"`
+}
+
+func (*SQLiteDialect) BindType() int {
+	return sqlx.QUESTION
+}
+
+func (*SQLiteDialect) JSONExtract(col, path string) string {
+	return fmt.Sprintf(`json_extract(%s, '$.%s')`, col, path)
+}
+
+func (*SQLiteDialect) InsertReturning(string) string {
+	return ""
+}
+
+func (d *SQLiteDialect) Upsert(table, insert string, conflictCols, updateCols, increaseCols []string) (string, error) {
+	return onConflictUpsert(d, table, insert, conflictCols, updateCols, increaseCols)
+}
+
+// MaxBindVars the default SQLITE_MAX_VARIABLE_NUMBER before sqlite 3.32.0.
+func (*SQLiteDialect) MaxBindVars() int {
+	return 999
+}
+
+func (*SQLiteDialect) MaxPacketSize() int {
+	return 0
+}
+
+// BatchInsertIDs LastInsertId is the rowid of the last row, the rowids are consecutive
+// because writing is serialized in sqlite.
+func (*SQLiteDialect) BatchInsertIDs(lastInsertID int64, rows int) []int64 {
+	ids := make([]int64, 0, rows)
+	for i := rows - 1; i >= 0; i-- {
+		ids = append(ids, lastInsertID-int64(i))
+	}
+
+	return ids
+}
+
+func (*SQLiteDialect) LimitOffset(limit, offset int64) string {
+	switch {
+	case limit > 0 && offset > 0:
+		return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+	case limit > 0:
+		return fmt.Sprintf("LIMIT %d", limit)
+	case offset > 0:
+		return fmt.Sprintf("LIMIT -1 OFFSET %d", offset)
+	default:
+		return ""
+	}
+}
+
+func (d *SQLiteDialect) TableColumns(ctx context.Context, q sqlx.QueryerContext, table string) ([]*ColSchema, error) {
+	rows, err := q.QueryxContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", d.Quote(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ret []*ColSchema
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return nil, err
+		}
+		ret = append(ret, &ColSchema{Name: name, Type: colType, NotNull: notNull != 0, Primary: pk > 0})
+	}
+
+	return ret, rows.Err()
+}
+
+// TableIndexes the `INTEGER PRIMARY KEY` column has no index, it's returned from table info.
+func (d *SQLiteDialect) TableIndexes(ctx context.Context, q sqlx.QueryerContext, table string) ([][]string, error) {
+	query := fmt.Sprintf("SELECT il.name, ii.name FROM pragma_index_list(%s) AS il, pragma_index_info(il.name) AS ii "+
+		"ORDER BY il.name, ii.seqno", d.quoteString(table))
+	rows, err := q.QueryxContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret, err := scanIndexCols(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	cols, err := d.TableColumns(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+	var pCols []string
+	for _, c := range cols {
+		if c.Primary {
+			pCols = append(pCols, c.Name)
+		}
+	}
+	if len(pCols) > 0 {
+		ret = append(ret, pCols)
+	}
+
+	return ret, nil
+}
+
+// TableNames the internal tables prefixed by `sqlite_` are excluded.
+func (*SQLiteDialect) TableNames(ctx context.Context, q sqlx.QueryerContext) ([]string, error) {
+	query := `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite!_%' ESCAPE '!' ORDER BY name`
+
+	return scanTableNames(ctx, q, query)
+}
+
+// ModifyColumnDDL sqlite not support altering column, the table should be rebuilt manually.
+func (*SQLiteDialect) ModifyColumnDDL(table string, c *ColSchema) ([]string, error) {
+	return nil, &ErrorSQLInvalid{Message: fmt.Sprintf("sqlite not support modifying column %s of table %s", c.Name, table)}
+}
+
+// quoteString quote string literal.
+func (*SQLiteDialect) quoteString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+func (*SQLiteDialect) IsTableNotExist(err error) bool {
+	return err != nil && sqliteTableNotExistRegex.MatchString(err.Error())
+}
+
+func (*SQLiteDialect) IsDupKey(err error) bool {
+	return err != nil && sqliteDupKeyRegex.MatchString(err.Error())
+}
+
+// BinaryStringOrder the default collation BINARY compares strings by bytes.
+func (*SQLiteDialect) BinaryStringOrder() bool {
+	return true
+}
+
+func (*SQLiteDialect) DDLCommitsTx() bool {
+	return false
+}
+
+func (*SQLiteDialect) ErrorAbortsTx() bool {
+	return false
+}
+
+// scanIndexCols scan the rows of index name and column name ordered by index name, return columns of each index.
+func scanIndexCols(rows *sqlx.Rows) ([][]string, error) {
+	var ret [][]string
+	var lastIndex string
+	for rows.Next() {
+		var index, col string
+		if err := rows.Scan(&index, &col); err != nil {
+			return nil, err
+		}
+
+		if len(ret) == 0 || index != lastIndex {
+			ret = append(ret, nil)
+			lastIndex = index
+		}
+		ret[len(ret)-1] = append(ret[len(ret)-1], col)
+	}
+
+	return ret, rows.Err()
+}
+
+// scanTableNames return the table names queried in the first column.
+func scanTableNames(ctx context.Context, q sqlx.QueryerContext, query string) ([]string, error) {
+	rows, err := q.QueryxContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ret []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		ret = append(ret, name)
+	}
+
+	return ret, rows.Err()
+}
+
+// indexCreateSQL return the standalone index creating statement with the quoted index and table names.
+func indexCreateSQL(d Dialect, index, table string, cols []string, unique bool) string {
+	tpl := indexCreateSQLTpl
+	if unique {
+		tpl = uniqueIndexCreateSQLTpl
+	}
+
+	return fmt.Sprintf(tpl, index, table, strings.Join(quoteIdentifiers(d, cols), ","))
+}
+
+// splitTableName split the schema qualified table name, schema is empty when not qualified.
+func splitTableName(table string) (schema, name string) {
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		return table[:i], table[i+1:]
+	}
+
+	return "", table
+}
+
+// onConflictUpsert compose upsert statement with `ON CONFLICT` clause, which is supported by sqlite and postgresql.
+func onConflictUpsert(d Dialect, table, insert string, conflictCols, updateCols, increaseCols []string) (string, error) {
+	if len(conflictCols) == 0 {
+		return "", &ErrorSQLInvalid{Message: "table schema should has primary or unique col setted for upsert"}
+	}
+
+	var updatePatterns []string
+	for _, k := range updateCols {
+		updatePatterns = append(updatePatterns, fmt.Sprintf("%s=EXCLUDED.%s", d.Quote(k), d.Quote(k)))
+	}
+	if len(updatePatterns) > 0 {
+		// the exist value is referenced by table name, the unqualified column is ambiguous in postgres.
+		for _, k := range increaseCols {
+			updatePatterns = append(updatePatterns, fmt.Sprintf("%s=%s.%s+1", d.Quote(k), quoteTableName(d, table), d.Quote(k)))
+		}
+	}
+
+	conflicts := strings.Join(quoteIdentifiers(d, conflictCols), ",")
+	if len(updatePatterns) == 0 {
+		return fmt.Sprintf("%s ON CONFLICT (%s) DO NOTHING", insert, conflicts), nil
+	}
+
+	upsertTpl := "%s ON CONFLICT (%s) DO UPDATE SET %s"
+	return fmt.Sprintf(upsertTpl, insert, conflicts, strings.Join(updatePatterns, ",")), nil
+}
@@ -0,0 +1,280 @@
+package sqlm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// testDialect dialect for unit testing, it's a variant of mysql.
+type testDialect struct {
+	MySQLDialect
+}
+
+func (*testDialect) ColumnDDL(c *ColSchema, _ bool) string {
+	return c.Name + " " + c.Type + " COMMENT 'test'"
+}
+
+func TestRegisterDialect(t *testing.T) {
+	RegisterDialect("test", new(testDialect))
+	defer UnRegisterDialect("test")
+
+	t.Run("registered", func(t *testing.T) {
+		want := []string{"mysql", "postgres", "sqlite", "sqlite3", "test"}
+		if got := Dialects(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Dialects() = %v, want %v", got, want)
+		}
+
+		s := &TableSchema{Driver: "test", Name: "t", Columns: []*ColSchema{{Name: "a", Type: "INT"}}}
+		if got, want := s.CreateSQL(), "CREATE TABLE IF NOT EXISTS `t` (\na INT COMMENT 'test'\n)"; got != want {
+			t.Errorf("TableSchema.CreateSQL() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("register twice", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("RegisterDialect() twice should panic")
+			}
+		}()
+		RegisterDialect("test", new(testDialect))
+	})
+
+	t.Run("register nil", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("RegisterDialect() nil should panic")
+			}
+		}()
+		RegisterDialect("test2", nil)
+	})
+
+	t.Run("not registered", func(t *testing.T) {
+		if _, err := GetDialect("not_exist"); err == nil {
+			t.Errorf("GetDialect() error = %v, wantErr %v", err, true)
+		}
+	})
+}
+
+func TestDialects(t *testing.T) {
+	tests := []struct {
+		driver          string
+		wantQuote       string
+		wantBindType    int
+		wantJSONExtract string
+		wantLimitOffset []string // limit only, offset only, both
+		tableNotExist   string
+		dupKey          string
+		wantReturning   string
+		wantMaxBindVars int
+		wantBatchIDs    []int64 // ids of 3 rows with LastInsertId 10
+	}{
+		{
+			DriverMysql,
+			"`a``b`",
+			sqlx.QUESTION,
+			`JSON_EXTRACT(h, "$.a.b")`,
+			[]string{"LIMIT 10", "LIMIT 18446744073709551615 OFFSET 20", "LIMIT 10 OFFSET 20"},
+			"Error 1146: Table 'fake.test' doesn't exist",
+			"Error 1062: Duplicate entry '1' for key 'PRIMARY'",
+			"",
+			65535,
+			nil,
+		},
+		{
+			DriverSQLite3,
+			"\"a`b\"",
+			sqlx.QUESTION,
+			`json_extract(h, '$.a.b')`,
+			[]string{"LIMIT 10", "LIMIT -1 OFFSET 20", "LIMIT 10 OFFSET 20"},
+			"no such table: test",
+			"UNIQUE constraint failed: test.id",
+			"",
+			999,
+			[]int64{8, 9, 10},
+		},
+		{
+			DriverPostgres,
+			"\"a`b\"",
+			sqlx.DOLLAR,
+			`CAST(h AS JSONB) #>> '{a,b}'`,
+			[]string{"LIMIT 10", "OFFSET 20", "LIMIT 10 OFFSET 20"},
+			`pq: relation "test" does not exist`,
+			`pq: duplicate key value violates unique constraint "test_pkey"`,
+			`RETURNING "id"`,
+			65535,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			d, err := GetDialect(tt.driver)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := d.Quote("a`b"); got != tt.wantQuote {
+				t.Errorf("Dialect.Quote() = %v, want %v", got, tt.wantQuote)
+			}
+			if got := d.BindType(); got != tt.wantBindType {
+				t.Errorf("Dialect.BindType() = %v, want %v", got, tt.wantBindType)
+			}
+			if got := d.JSONExtract("h", "a.b"); got != tt.wantJSONExtract {
+				t.Errorf("Dialect.JSONExtract() = %v, want %v", got, tt.wantJSONExtract)
+			}
+			gotLimitOffset := []string{d.LimitOffset(10, 0), d.LimitOffset(0, 20), d.LimitOffset(10, 20)}
+			if !reflect.DeepEqual(gotLimitOffset, tt.wantLimitOffset) {
+				t.Errorf("Dialect.LimitOffset() = %v, want %v", gotLimitOffset, tt.wantLimitOffset)
+			}
+			if d.LimitOffset(0, 0) != "" {
+				t.Errorf("Dialect.LimitOffset() without limit and offset should be empty")
+			}
+			if !d.IsTableNotExist(errors.New(tt.tableNotExist)) {
+				t.Errorf("Dialect.IsTableNotExist(%q) = false, want true", tt.tableNotExist)
+			}
+			if d.IsTableNotExist(nil) || d.IsTableNotExist(errors.New("other error")) {
+				t.Errorf("Dialect.IsTableNotExist() should be false for other errors")
+			}
+			dupKey := d.(DupKeyDialect)
+			if !dupKey.IsDupKey(errors.New(tt.dupKey)) || dupKey.IsDupKey(errors.New(tt.tableNotExist)) {
+				t.Errorf("Dialect.IsDupKey(%q) = false, want true", tt.dupKey)
+			}
+			if got := d.InsertReturning("id"); got != tt.wantReturning {
+				t.Errorf("Dialect.InsertReturning() = %v, want %v", got, tt.wantReturning)
+			}
+			batch := d.(BatchDialect)
+			if got := batch.MaxBindVars(); got != tt.wantMaxBindVars {
+				t.Errorf("Dialect.MaxBindVars() = %v, want %v", got, tt.wantMaxBindVars)
+			}
+			if got := batch.BatchInsertIDs(10, 3); !reflect.DeepEqual(got, tt.wantBatchIDs) {
+				t.Errorf("Dialect.BatchInsertIDs() = %v, want %v", got, tt.wantBatchIDs)
+			}
+		})
+	}
+}
+
+func Test_quoteIdentifier(t *testing.T) {
+	tests := []struct {
+		name       string
+		d          Dialect
+		identifier string
+		want       string
+	}{
+		{"nil dialect", nil, "order", "order"},
+		{"mysql", new(MySQLDialect), "order", "`order`"},
+		{"sqlite", new(SQLiteDialect), "group", `"group"`},
+		{"qualified", new(PostgresDialect), "t.key", `"t"."key"`},
+		{"star", new(MySQLDialect), "*", "*"},
+		{"expression", new(MySQLDialect), "count(*)", "count(*)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteIdentifier(tt.d, tt.identifier); got != tt.want {
+				t.Errorf("quoteIdentifier() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_quoteTableName(t *testing.T) {
+	tests := []struct {
+		name  string
+		d     Dialect
+		table string
+		want  string
+	}{
+		{"nil dialect", nil, "t_x-y", "t_x-y"},
+		{"plain", new(MySQLDialect), "order", "`order`"},
+		{"split value with hyphen", new(SQLiteDialect), "t_x-y", `"t_x-y"`},
+		{"qualified", new(PostgresDialect), "s.t_x-y", `"s"."t_x-y"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteTableName(tt.d, tt.table); got != tt.want {
+				t.Errorf("quoteTableName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// basicDialect dialect for unit testing, it implements none of the optional interfaces.
+type basicDialect struct {
+	Dialect
+}
+
+// replaceTestDialect replace the dialect of driver with the wrapped one until the test finished.
+func replaceTestDialect(t *testing.T, driver string, wrap func(d Dialect) Dialect) {
+	t.Helper()
+
+	builtin, err := GetDialect(driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	UnRegisterDialect(driver)
+	RegisterDialect(driver, wrap(builtin))
+	t.Cleanup(func() {
+		UnRegisterDialect(driver)
+		RegisterDialect(driver, builtin)
+	})
+}
+
+func TestRegisterDialect_basic(t *testing.T) {
+	replaceTestDialect(t, DriverSQLite3, func(d Dialect) Dialect { return basicDialect{d} })
+
+	table := newTestSQLiteTable(t, "test_basic_dialect", &testSimpleRecord{})
+	records := []interface{}{&testSimpleRecord{Name: "a"}, &testSimpleRecord{Name: "b"}}
+	ids, err := table.Inserts(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1, 2}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Table.Inserts() = %v, want %v", ids, want)
+	}
+
+	if _, err := table.Migrate(MigrateOptions{DryRun: true}); err == nil {
+		t.Errorf("Table.Migrate() error = nil, want error of dialect not supported")
+	}
+	if _, err := table.ShardTables(); err == nil {
+		t.Errorf("Table.ShardTables() error = nil, want error of dialect not supported")
+	}
+}
+
+func TestDialect_IndexDDL(t *testing.T) {
+	tests := []struct {
+		name  string
+		d     Dialect
+		table string
+		want  string
+	}{
+		{"sqlite", new(SQLiteDialect), "t", `CREATE INDEX IF NOT EXISTS "t_a" ON "t" ("a")`},
+		{"sqlite qualified", new(SQLiteDialect), "main.t", `CREATE INDEX IF NOT EXISTS "main"."t_a" ON "t" ("a")`},
+		{"postgres qualified", new(PostgresDialect), "s.t", `CREATE INDEX IF NOT EXISTS "t_a" ON "s"."t" ("a")`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := tt.d.IndexDDL(tt.table, "a", []string{"a"}, false); got != tt.want {
+				t.Errorf("Dialect.IndexDDL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	table := newTestSQLiteTable(t, "test_index_qualified", &testSimpleRecord{})
+	ddl, _ := new(SQLiteDialect).IndexDDL("main.test_index_qualified", "score", []string{"score"}, false)
+	if err := testExec(t, table.Database, ddl); err != nil {
+		t.Errorf("exec %s failed: %v", ddl, err)
+	}
+}
+
+func Test_onConflictUpsert_qualified(t *testing.T) {
+	got, err := onConflictUpsert(new(PostgresDialect), "s.t", `INSERT INTO "s"."t" ("a","v") VALUES (:a,:v)`,
+		[]string{"a"}, []string{"b"}, []string{"v"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `INSERT INTO "s"."t" ("a","v") VALUES (:a,:v) ON CONFLICT ("a") DO UPDATE SET "b"=EXCLUDED."b","v"="s"."t"."v"+1`
+	if got != want {
+		t.Errorf("onConflictUpsert() = %v, want %v", got, want)
+	}
+}
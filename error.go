@@ -1,5 +1,10 @@
 package sqlm
 
+import (
+	"fmt"
+	"strings"
+)
+
 // ErrorSQLInvalid error when composed invalid sql statement
 type ErrorSQLInvalid struct {
 	Message string
@@ -15,3 +20,50 @@ func (e *ErrorSQLInvalid) Error() string {
 func (e *ErrorSQLInvalid) Unwrap() error {
 	return e.Err
 }
+
+// ErrorSplitColMissing error when the split column is absent in filter, so the target table can not be computed.
+type ErrorSplitColMissing struct {
+	Col string
+}
+
+// Error error message
+func (e *ErrorSplitColMissing) Error() string {
+	return fmt.Sprintf("col %s is required in where patterns for compute target table name", e.Col)
+}
+
+// ErrStaleRecord error when saving the record whose version is changed by others, or the record is deleted.
+type ErrStaleRecord struct {
+	Table   string
+	Version interface{}
+}
+
+// Error error message
+func (e *ErrStaleRecord) Error() string {
+	return fmt.Sprintf("record in table %s is stale, version %v is changed or the record is deleted", e.Table, e.Version)
+}
+
+// ErrorMigrateUnsupported error when the live table has changes which the dialect can not migrate, such as modifying
+// columns of sqlite, the changes are marked as comments in the planned statements.
+type ErrorMigrateUnsupported struct {
+	Table string
+	Errs  []error
+}
+
+// Error error message
+func (e *ErrorMigrateUnsupported) Error() string {
+	msgs := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		msgs = append(msgs, err.Error())
+	}
+
+	return fmt.Sprintf("migrating table %s is not supported: %s", e.Table, strings.Join(msgs, "; "))
+}
+
+// Unwrap return the first source error
+func (e *ErrorMigrateUnsupported) Unwrap() error {
+	if len(e.Errs) == 0 {
+		return nil
+	}
+
+	return e.Errs[0]
+}
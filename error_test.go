@@ -42,3 +42,11 @@ func TestErrorSQLInvalid_Unwrap(t *testing.T) {
 		})
 	}
 }
+
+func TestErrStaleRecord_Error(t *testing.T) {
+	err := &ErrStaleRecord{Table: "test", Version: 3}
+	want := "record in table test is stale, version 3 is changed or the record is deleted"
+	if got := err.Error(); got != want {
+		t.Errorf("ErrStaleRecord.Error() = %v, want %v", got, want)
+	}
+}
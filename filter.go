@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -24,11 +25,53 @@ type RowFilter interface {
 	WherePattern() (*SQLWhere, error)
 }
 
+// DialectRowFilter for filters which compose different where statements for sql dialects.
+type DialectRowFilter interface {
+	RowFilter
+	DialectWherePattern(d Dialect) (*SQLWhere, error)
+}
+
+// EqualRowFilter for filters which restrict columns to be equal to values, the values are used to
+// route the split tables. The built-in filters implement it, the custom filters not implementing it
+// are routed by the patterns of their where statements.
+type EqualRowFilter interface {
+	RowFilter
+	EqualPatterns() (map[string]interface{}, error)
+}
+
+// equalPatterns return the columns restricted to be equal to values by the filter, nil when none.
+func equalPatterns(rf RowFilter) (map[string]interface{}, error) {
+	if f, ok := rf.(EqualRowFilter); ok {
+		return f.EqualPatterns()
+	}
+
+	where, err := rf.WherePattern()
+	if err != nil || where == nil {
+		return nil, err
+	}
+
+	return where.Patterns, nil
+}
+
+// WherePatternWithDialect compose where statement of the filter for the dialect.
+func WherePatternWithDialect(rf RowFilter, d Dialect) (*SQLWhere, error) {
+	if f, ok := rf.(DialectRowFilter); ok && d != nil {
+		return f.DialectWherePattern(d)
+	}
+
+	return rf.WherePattern()
+}
+
 // RowFilterAnd for compose filters
 type RowFilterAnd []RowFilter
 
 // WherePattern imp for RowFilter interface
 func (f RowFilterAnd) WherePattern() (*SQLWhere, error) {
+	return f.DialectWherePattern(nil)
+}
+
+// DialectWherePattern imp for DialectRowFilter interface
+func (f RowFilterAnd) DialectWherePattern(d Dialect) (*SQLWhere, error) {
 	if len(f) == 0 {
 		return nil, nil
 	}
@@ -36,48 +79,201 @@ func (f RowFilterAnd) WherePattern() (*SQLWhere, error) {
 	ret := SQLWhere{Patterns: make(map[string]interface{})}
 
 	for _, e := range f {
-		if e == nil {
-			continue
-		}
-
-		eRet, err := e.WherePattern()
+		eRet, err := composeChildWhere(&ret, e, d)
 		if err != nil {
-			return eRet, err
+			return nil, err
 		}
-		if eRet == nil {
+		if eRet.Format == "" {
 			continue
 		}
-		if eRet.Join != nil {
-			return nil, fmt.Errorf("not support table join query in filters combining")
-		}
+
 		if ret.Format == "" {
 			ret.Format = eRet.Format
 		} else {
 			ret.Format += " AND (" + eRet.Format + ")"
 		}
-		for k, v := range eRet.Patterns {
-			ret.Patterns[k] = v
+	}
+
+	return &ret, nil
+}
+
+// EqualPatterns imp for EqualRowFilter interface, the columns restricted to different values by children are dropped.
+func (f RowFilterAnd) EqualPatterns() (map[string]interface{}, error) {
+	ret := make(map[string]interface{})
+	conflicted := make(map[string]bool)
+	for _, e := range f {
+		if e == nil {
+			continue
 		}
+
+		patterns, err := equalPatterns(e)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range patterns {
+			if exist, ok := ret[k]; ok && !reflect.DeepEqual(exist, v) {
+				conflicted[k] = true
+			}
+			ret[k] = v
+		}
+	}
+	for k := range conflicted {
+		delete(ret, k)
 	}
 
+	return ret, nil
+}
+
+// RowFilterOr for compose filters with `OR`.
+type RowFilterOr []RowFilter
+
+// EqualPatterns imp for EqualRowFilter interface, no columns are restricted to be equal.
+func (RowFilterOr) EqualPatterns() (map[string]interface{}, error) {
+	return nil, nil
+}
+
+// WherePattern imp for RowFilter interface
+func (f RowFilterOr) WherePattern() (*SQLWhere, error) {
+	return f.DialectWherePattern(nil)
+}
+
+// DialectWherePattern imp for DialectRowFilter interface
+func (f RowFilterOr) DialectWherePattern(d Dialect) (*SQLWhere, error) {
+	if len(f) == 0 {
+		return nil, nil
+	}
+
+	ret := SQLWhere{Patterns: make(map[string]interface{})}
+
+	var formats []string
+	for _, e := range f {
+		if e == nil {
+			continue
+		}
+
+		eRet, err := composeChildWhere(&ret, e, d)
+		if err != nil {
+			return nil, err
+		}
+		if eRet.Format == "" {
+			// 存在无条件的子过滤器, 则整体不过滤
+			return nil, nil
+		}
+
+		formats = append(formats, "("+eRet.Format+")")
+	}
+	if len(formats) == 0 {
+		return nil, nil
+	}
+
+	ret.Format = "(" + strings.Join(formats, " OR ") + ")"
 	return &ret, nil
 }
 
-// LikeFilter for like filter
+// NotFilter negate the filter.
+type NotFilter struct {
+	Filter RowFilter
+}
+
+// EqualPatterns imp for EqualRowFilter interface, no columns are restricted to be equal.
+func (NotFilter) EqualPatterns() (map[string]interface{}, error) {
+	return nil, nil
+}
+
+// WherePattern imp for RowFilter interface
+func (f NotFilter) WherePattern() (*SQLWhere, error) {
+	return f.DialectWherePattern(nil)
+}
+
+// DialectWherePattern imp for DialectRowFilter interface
+func (f NotFilter) DialectWherePattern(d Dialect) (*SQLWhere, error) {
+	if f.Filter == nil {
+		return nil, errors.New("should set Filter")
+	}
+
+	where, err := WherePatternWithDialect(f.Filter, d)
+	if err != nil {
+		return nil, err
+	}
+	if where == nil || where.Format == "" {
+		return nil, errors.New("not support negating filter without where condition")
+	}
+	if where.Join != nil {
+		return nil, fmt.Errorf("not support table join query in filters combining")
+	}
+
+	return &SQLWhere{Format: "NOT (" + where.Format + ")", Patterns: where.Patterns}, nil
+}
+
+// composeChildWhere compose where statement of child filter e and merge its patterns into ret,
+// the child pattern names already existed in ret are renamed and the returned child format is rewritten.
+// The returned format is empty when child is nil or has not where condition.
+func composeChildWhere(ret *SQLWhere, e RowFilter, d Dialect) (*SQLWhere, error) {
+	if e == nil {
+		return &SQLWhere{}, nil
+	}
+
+	eRet, err := WherePatternWithDialect(e, d)
+	if err != nil {
+		return nil, err
+	}
+	if eRet == nil {
+		return &SQLWhere{}, nil
+	}
+	if eRet.Join != nil {
+		return nil, fmt.Errorf("not support table join query in filters combining")
+	}
+
+	format, err := mergeWhere(ret, eRet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLWhere{Format: format}, nil
+}
+
+// LikeFilter for like filter, the value is matched with any one of columns Key and Keys.
 type LikeFilter struct {
 	Key   string
+	Keys  []string
 	Value string
 }
 
+// EqualPatterns imp for EqualRowFilter interface, no columns are restricted to be equal.
+func (LikeFilter) EqualPatterns() (map[string]interface{}, error) {
+	return nil, nil
+}
+
 // WherePattern imp for RowFilter interface
 func (l LikeFilter) WherePattern() (*SQLWhere, error) {
-	if l.Key == "" || l.Value == "" {
+	return l.DialectWherePattern(nil)
+}
+
+// DialectWherePattern imp for DialectRowFilter interface
+func (l LikeFilter) DialectWherePattern(d Dialect) (*SQLWhere, error) {
+	var keys []string
+	if l.Key != "" {
+		keys = append(keys, l.Key)
+	}
+	keys = append(keys, l.Keys...)
+	if len(keys) == 0 || l.Value == "" {
 		return nil, errors.New("lack key or value")
 	}
 
-	format := fmt.Sprintf("%s like :%s", l.Key, l.Key)
-	patterns := map[string]interface{}{l.Key: l.Value}
-	return &SQLWhere{Format: format, Patterns: patterns}, nil
+	var formats []string
+	patterns := make(map[string]interface{})
+	for _, k := range keys {
+		if k == "" {
+			return nil, errors.New("lack key or value")
+		}
+		formats = append(formats, fmt.Sprintf("%s like :%s", quoteIdentifier(d, k), k))
+		patterns[k] = l.Value
+	}
+
+	if len(formats) == singlePKCount {
+		return &SQLWhere{Format: formats[0], Patterns: patterns}, nil
+	}
+	return &SQLWhere{Format: "(" + strings.Join(formats, " OR ") + ")", Patterns: patterns}, nil
 }
 
 // SelectorFilter simple map selector filter
@@ -85,17 +281,33 @@ type SelectorFilter map[string]interface{}
 
 // WherePattern imp for RowFilter interface
 func (f SelectorFilter) WherePattern() (*SQLWhere, error) {
+	return f.DialectWherePattern(nil)
+}
+
+// DialectWherePattern imp for DialectRowFilter interface
+func (f SelectorFilter) DialectWherePattern(d Dialect) (*SQLWhere, error) {
 	if len(f) == 0 {
 		return nil, nil
 	}
 
-	var whereFormatter []string
+	keys := make([]string, 0, len(f))
 	for k := range f {
-		whereFormatter = append(whereFormatter, fmt.Sprintf("%s=:%s", k, k))
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var whereFormatter []string
+	for _, k := range keys {
+		whereFormatter = append(whereFormatter, fmt.Sprintf("%s=:%s", quoteIdentifier(d, k), k))
 	}
 	return &SQLWhere{Format: strings.Join(whereFormatter, " AND "), Patterns: f}, nil
 }
 
+// EqualPatterns imp for EqualRowFilter interface
+func (f SelectorFilter) EqualPatterns() (map[string]interface{}, error) {
+	return f, nil
+}
+
 // TimeFormatFn for time object
 type TimeFormatFn func(time.Time) interface{}
 
@@ -106,8 +318,18 @@ type BetweenFilter struct {
 	To   interface{}
 }
 
+// EqualPatterns imp for EqualRowFilter interface, no columns are restricted to be equal.
+func (BetweenFilter) EqualPatterns() (map[string]interface{}, error) {
+	return nil, nil
+}
+
 // WherePattern imp for RowFilter interface
 func (f BetweenFilter) WherePattern() (*SQLWhere, error) {
+	return f.DialectWherePattern(nil)
+}
+
+// DialectWherePattern imp for DialectRowFilter interface
+func (f BetweenFilter) DialectWherePattern(d Dialect) (*SQLWhere, error) {
 	if f.Col == "" {
 		return nil, errors.New("empty col")
 	}
@@ -121,7 +343,7 @@ func (f BetweenFilter) WherePattern() (*SQLWhere, error) {
 	patterns := make(map[string]interface{})
 	fromKey := f.Col + "S"
 	toKey := f.Col + "E"
-	formatter := fmt.Sprintf("%s BETWEEN :%s AND :%s", f.Col, fromKey, toKey)
+	formatter := fmt.Sprintf("%s BETWEEN :%s AND :%s", quoteIdentifier(d, f.Col), fromKey, toKey)
 	patterns[fromKey] = f.From
 	patterns[toKey] = f.To
 
@@ -134,9 +356,139 @@ type ColListFilter struct {
 	Values []interface{}
 }
 
+// EqualPatterns imp for EqualRowFilter interface, no columns are restricted to be equal.
+func (ColListFilter) EqualPatterns() (map[string]interface{}, error) {
+	return nil, nil
+}
+
 // WherePattern return the parts for compose sql update/delete query
 func (f ColListFilter) WherePattern() (*SQLWhere, error) {
-	return whereListPattern(f.Col, f.Values)
+	return f.DialectWherePattern(nil)
+}
+
+// DialectWherePattern imp for DialectRowFilter interface
+func (f ColListFilter) DialectWherePattern(d Dialect) (*SQLWhere, error) {
+	return whereListPattern(d, f.Col, f.Values)
+}
+
+// compare operators for CompareFilter.
+const (
+	OpEq = "="  // OpEq equal.
+	OpNe = "!=" // OpNe not equal.
+	OpLt = "<"  // OpLt less than.
+	OpLe = "<=" // OpLe less than or equal.
+	OpGt = ">"  // OpGt greater than.
+	OpGe = ">=" // OpGe greater than or equal.
+)
+
+// CompareFilter for col value compare filter, such as: `col > :col`.
+type CompareFilter struct {
+	Col   string
+	Op    string // one of: =, !=, <>, <, <=, >, >=
+	Value interface{}
+}
+
+// WherePattern imp for RowFilter interface
+func (f CompareFilter) WherePattern() (*SQLWhere, error) {
+	return f.DialectWherePattern(nil)
+}
+
+// DialectWherePattern imp for DialectRowFilter interface
+func (f CompareFilter) DialectWherePattern(d Dialect) (*SQLWhere, error) {
+	if f.Col == "" {
+		return nil, errors.New("empty col")
+	}
+	switch f.Op {
+	case OpEq, OpNe, "<>", OpLt, OpLe, OpGt, OpGe:
+	default:
+		return nil, fmt.Errorf("not supported compare operator: %q", f.Op)
+	}
+	if f.Value == nil {
+		return nil, errors.New("nil value, use NullFilter instead")
+	}
+
+	format := fmt.Sprintf("%s %s :%s", quoteIdentifier(d, f.Col), f.Op, f.Col)
+	return &SQLWhere{Format: format, Patterns: map[string]interface{}{f.Col: f.Value}}, nil
+}
+
+// EqualPatterns imp for EqualRowFilter interface, only the `=` operator restricts the col.
+func (f CompareFilter) EqualPatterns() (map[string]interface{}, error) {
+	if f.Op != OpEq || f.Col == "" || f.Value == nil {
+		return nil, nil
+	}
+
+	return map[string]interface{}{f.Col: f.Value}, nil
+}
+
+// NullFilter for col value is null or not.
+type NullFilter struct {
+	Col     string
+	NotNull bool
+}
+
+// EqualPatterns imp for EqualRowFilter interface, no columns are restricted to be equal.
+func (NullFilter) EqualPatterns() (map[string]interface{}, error) {
+	return nil, nil
+}
+
+// WherePattern imp for RowFilter interface
+func (f NullFilter) WherePattern() (*SQLWhere, error) {
+	return f.DialectWherePattern(nil)
+}
+
+// DialectWherePattern imp for DialectRowFilter interface
+func (f NullFilter) DialectWherePattern(d Dialect) (*SQLWhere, error) {
+	if f.Col == "" {
+		return nil, errors.New("empty col")
+	}
+
+	format := quoteIdentifier(d, f.Col) + " IS NULL"
+	if f.NotNull {
+		format = quoteIdentifier(d, f.Col) + " IS NOT NULL"
+	}
+	return &SQLWhere{Format: format, Patterns: map[string]interface{}{}}, nil
+}
+
+// InFilter for col value in list.
+type InFilter struct {
+	Col    string
+	Values []interface{}
+}
+
+// EqualPatterns imp for EqualRowFilter interface, no columns are restricted to be equal.
+func (InFilter) EqualPatterns() (map[string]interface{}, error) {
+	return nil, nil
+}
+
+// WherePattern imp for RowFilter interface
+func (f InFilter) WherePattern() (*SQLWhere, error) {
+	return f.DialectWherePattern(nil)
+}
+
+// DialectWherePattern imp for DialectRowFilter interface
+func (f InFilter) DialectWherePattern(d Dialect) (*SQLWhere, error) {
+	return whereInPattern(d, f.Col, "IN", f.Values)
+}
+
+// NotInFilter for col value not in list.
+type NotInFilter struct {
+	Col    string
+	Values []interface{}
+}
+
+// EqualPatterns imp for EqualRowFilter interface, no columns are restricted to be equal.
+func (NotInFilter) EqualPatterns() (map[string]interface{}, error) {
+	return nil, nil
+}
+
+// WherePattern imp for RowFilter interface
+func (f NotInFilter) WherePattern() (*SQLWhere, error) {
+	return f.DialectWherePattern(nil)
+}
+
+// DialectWherePattern imp for DialectRowFilter interface
+func (f NotInFilter) DialectWherePattern(d Dialect) (*SQLWhere, error) {
+	return whereInPattern(d, f.Col, "NOT IN", f.Values)
 }
 
 // HashColFilter for db json col with hash type
@@ -145,19 +497,35 @@ type HashColFilter struct {
 	Value HashCol
 }
 
-// WherePattern imp for RowFilter interface
+// EqualPatterns imp for EqualRowFilter interface, no columns are restricted to be equal.
+func (HashColFilter) EqualPatterns() (map[string]interface{}, error) {
+	return nil, nil
+}
+
+// WherePattern imp for RowFilter interface, json value is extracted in mysql style.
 func (f HashColFilter) WherePattern() (*SQLWhere, error) {
+	return f.wherePattern(new(MySQLDialect), nil)
+}
+
+// DialectWherePattern imp for DialectRowFilter interface
+func (f HashColFilter) DialectWherePattern(d Dialect) (*SQLWhere, error) {
+	return f.wherePattern(d, d)
+}
+
+// wherePattern compose where statement with json extracting of dialect d, col is quoted by dialect q.
+func (f HashColFilter) wherePattern(d, q Dialect) (*SQLWhere, error) {
 	if f.Col == "" {
 		return nil, fmt.Errorf("should set Col")
 	}
+	col := quoteIdentifier(q, f.Col)
 	if len(f.Value) == 0 {
-		return &SQLWhere{Format: fmt.Sprintf("(%s='%s' OR %s=NULL)", f.Col, "{}", f.Col)}, nil
+		return &SQLWhere{Format: fmt.Sprintf("(%s='%s' OR %s=NULL)", col, "{}", col)}, nil
 	}
 
 	var whereFormatter []string
 	patterns := map[string]interface{}{}
 	for k, v := range f.Value {
-		childKey := fmt.Sprintf("JSON_EXTRACT(%s, \"$.%s\")", f.Col, k)
+		childKey := d.JSONExtract(col, k)
 		childPattern := fmt.Sprintf("%s_%s", f.Col, k)
 		formatter := fmt.Sprintf("%s=:%s", childKey, childPattern)
 		whereFormatter = append(whereFormatter, formatter)
@@ -169,13 +537,28 @@ func (f HashColFilter) WherePattern() (*SQLWhere, error) {
 // IDListFilter id list filter
 type IDListFilter []int32
 
+// EqualPatterns imp for EqualRowFilter interface, no columns are restricted to be equal.
+func (IDListFilter) EqualPatterns() (map[string]interface{}, error) {
+	return nil, nil
+}
+
 // WherePattern return the parts for compose sql update/delete query
 func (f IDListFilter) WherePattern() (*SQLWhere, error) {
-	return whereListPattern("id", linq.From(f).Results())
+	return f.DialectWherePattern(nil)
+}
+
+// DialectWherePattern imp for DialectRowFilter interface
+func (f IDListFilter) DialectWherePattern(d Dialect) (*SQLWhere, error) {
+	return whereListPattern(d, "id", linq.From(f).Results())
 }
 
-// whereListPattern
-func whereListPattern(key string, values []interface{}) (*SQLWhere, error) {
+// whereListPattern compose where statement like `col IN (:col_0, :col_1, ...)` with values bound by patterns.
+func whereListPattern(d Dialect, key string, values []interface{}) (*SQLWhere, error) {
+	return whereInPattern(d, key, "IN", values)
+}
+
+// whereInPattern compose where statement like `col <op> (:col_0, :col_1, ...)`, op is `IN` or `NOT IN`.
+func whereInPattern(d Dialect, key, op string, values []interface{}) (*SQLWhere, error) {
 	if key == "" {
 		return nil, fmt.Errorf("key filter should set key name")
 	}
@@ -183,17 +566,16 @@ func whereListPattern(key string, values []interface{}) (*SQLWhere, error) {
 		return nil, fmt.Errorf("key filter should contain one key val least")
 	}
 
-	var whereFormatter []string
-	for _, id := range values {
-		switch id.(type) {
-		case string:
-			whereFormatter = append(whereFormatter, fmt.Sprintf("%s='%s'", key, id))
-		default:
-			whereFormatter = append(whereFormatter, fmt.Sprintf("%s=%v", key, id))
-		}
+	placeholders := make([]string, 0, len(values))
+	patterns := make(map[string]interface{}, len(values))
+	for i, v := range values {
+		pattern := fmt.Sprintf("%s_%d", key, i)
+		placeholders = append(placeholders, ":"+pattern)
+		patterns[pattern] = v
 	}
 
-	return &SQLWhere{Format: strings.Join(whereFormatter, " OR ")}, nil
+	format := fmt.Sprintf("%s %s (%s)", quoteIdentifier(d, key), op, strings.Join(placeholders, ", "))
+	return &SQLWhere{Format: format, Patterns: patterns}, nil
 }
 
 // StructFilter 使用结构体作为过滤器
@@ -204,12 +586,22 @@ type StructFilter struct {
 
 // WherePattern return the parts for compose sql update/delete query
 func (f StructFilter) WherePattern() (*SQLWhere, error) {
+	return f.DialectWherePattern(nil)
+}
+
+// DialectWherePattern imp for DialectRowFilter interface
+func (f StructFilter) DialectWherePattern(d Dialect) (*SQLWhere, error) {
 	filter, err := f.transFilter()
 	if err != nil {
 		return nil, err
 	}
 
-	return filter.WherePattern()
+	return filter.DialectWherePattern(d)
+}
+
+// EqualPatterns imp for EqualRowFilter interface
+func (f StructFilter) EqualPatterns() (map[string]interface{}, error) {
+	return f.transFilter()
 }
 
 func (f StructFilter) transFilter() (SelectorFilter, error) {
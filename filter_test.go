@@ -82,13 +82,19 @@ func TestFilterWherePattern(t *testing.T) {
 		{
 			"ColListFilter-stringList",
 			ColListFilter{Col: "abc", Values: []interface{}{"abc", "def"}},
-			&SQLWhere{Format: "abc='abc' OR abc='def'"},
+			&SQLWhere{
+				Format:   "abc IN (:abc_0, :abc_1)",
+				Patterns: map[string]interface{}{"abc_0": "abc", "abc_1": "def"},
+			},
 			false,
 		},
 		{
 			"ColListFilter-numberList",
 			ColListFilter{Col: "abc", Values: []interface{}{123, 456.123}},
-			&SQLWhere{Format: "abc=123 OR abc=456.123"},
+			&SQLWhere{
+				Format:   "abc IN (:abc_0, :abc_1)",
+				Patterns: map[string]interface{}{"abc_0": 123, "abc_1": 456.123},
+			},
 			false,
 		},
 		{"HashColFilter-empty", HashColFilter{}, nil, true},
@@ -111,7 +117,10 @@ func TestFilterWherePattern(t *testing.T) {
 		{
 			"IDListFilter-valid",
 			IDListFilter{123, 456},
-			&SQLWhere{Format: "id=123 OR id=456"},
+			&SQLWhere{
+				Format:   "id IN (:id_0, :id_1)",
+				Patterns: map[string]interface{}{"id_0": int32(123), "id_1": int32(456)},
+			},
 			false,
 		},
 		{
@@ -134,6 +143,57 @@ func TestFilterWherePattern(t *testing.T) {
 		},
 		{"LikeFilter-empty-key", LikeFilter{Value: "%abcd_"}, nil, true},
 		{"LikeFilter-empty-value", LikeFilter{Key: "a"}, nil, true},
+		{
+			"LikeFilter-multi-keys",
+			LikeFilter{Key: "a", Keys: []string{"b"}, Value: "%abcd_"},
+			&SQLWhere{
+				Format:   `(a like :a OR b like :b)`,
+				Patterns: map[string]interface{}{"a": "%abcd_", "b": "%abcd_"},
+			},
+			false,
+		},
+		{
+			"CompareFilter-ok",
+			CompareFilter{Col: "a", Op: OpGe, Value: 1},
+			&SQLWhere{Format: "a >= :a", Patterns: map[string]interface{}{"a": 1}},
+			false,
+		},
+		{"CompareFilter-invalid-op", CompareFilter{Col: "a", Op: "; --", Value: 1}, nil, true},
+		{"CompareFilter-nil-value", CompareFilter{Col: "a", Op: OpEq}, nil, true},
+		{
+			"NullFilter-null",
+			NullFilter{Col: "a"},
+			&SQLWhere{Format: "a IS NULL", Patterns: map[string]interface{}{}},
+			false,
+		},
+		{
+			"NullFilter-not-null",
+			NullFilter{Col: "a", NotNull: true},
+			&SQLWhere{Format: "a IS NOT NULL", Patterns: map[string]interface{}{}},
+			false,
+		},
+		{"NullFilter-empty", NullFilter{}, nil, true},
+		{
+			"InFilter-ok",
+			InFilter{Col: "a", Values: []interface{}{1, 2}},
+			&SQLWhere{Format: "a IN (:a_0, :a_1)", Patterns: map[string]interface{}{"a_0": 1, "a_1": 2}},
+			false,
+		},
+		{
+			"NotInFilter-ok",
+			NotInFilter{Col: "a", Values: []interface{}{1}},
+			&SQLWhere{Format: "a NOT IN (:a_0)", Patterns: map[string]interface{}{"a_0": 1}},
+			false,
+		},
+		{"NotInFilter-empty", NotInFilter{Col: "a"}, nil, true},
+		{
+			"NotFilter-ok",
+			NotFilter{Filter: SelectorFilter{"a": 1}},
+			&SQLWhere{Format: "NOT (a=:a)", Patterns: map[string]interface{}{"a": 1}},
+			false,
+		},
+		{"NotFilter-empty", NotFilter{}, nil, true},
+		{"NotFilter-without-condition", NotFilter{Filter: SelectorFilter{}}, nil, true},
 	}
 
 	for _, tt := range tests {
@@ -141,6 +201,79 @@ func TestFilterWherePattern(t *testing.T) {
 	}
 }
 
+func TestWherePatternWithDialect(t *testing.T) {
+	tests := []struct {
+		name    string
+		f       RowFilter
+		driver  string
+		want    *SQLWhere
+		wantErr bool
+	}{
+		{
+			"HashColFilter-sqlite",
+			HashColFilter{Col: "h", Value: HashCol{"a": 123}},
+			DriverSQLite3,
+			&SQLWhere{
+				Format:   `json_extract("h", '$.a')=:h_a`,
+				Patterns: map[string]interface{}{"h_a": 123},
+			},
+			false,
+		},
+		{
+			"RowFilterAnd-postgres",
+			RowFilterAnd{SelectorFilter{"a": 1}, HashColFilter{Col: "h", Value: HashCol{"b": "x"}}},
+			DriverPostgres,
+			&SQLWhere{
+				Format:   `"a"=:a AND (CAST("h" AS JSONB) #>> '{b}'=:h_b)`,
+				Patterns: map[string]interface{}{"a": 1, "h_b": "x"},
+			},
+			false,
+		},
+		{
+			"SelectorFilter-postgres",
+			SelectorFilter{"a": 1},
+			DriverPostgres,
+			&SQLWhere{Format: `"a"=:a`, Patterns: SelectorFilter{"a": 1}},
+			false,
+		},
+		{
+			"LikeFilter-mysql-keyword",
+			LikeFilter{Key: "key", Value: "a%"},
+			DriverMysql,
+			&SQLWhere{Format: "`key` like :key", Patterns: map[string]interface{}{"key": "a%"}},
+			false,
+		},
+		{
+			"BetweenFilter-sqlite-keyword",
+			BetweenFilter{Col: "order", From: 1, To: 2},
+			DriverSQLite,
+			&SQLWhere{
+				Format:   `"order" BETWEEN :orderS AND :orderE`,
+				Patterns: map[string]interface{}{"orderS": 1, "orderE": 2},
+			},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := GetDialect(tt.driver)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := WherePatternWithDialect(tt.f, d)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WherePatternWithDialect() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WherePatternWithDialect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestStructFilter_wherePattern(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -262,6 +395,10 @@ func TestRowFilterAnd_WherePattern(t *testing.T) {
 			},
 		}, nil)
 
+	refRowFilter := NewMockRowFilter(mock)
+	refRowFilter.EXPECT().WherePattern().AnyTimes().
+		Return(&SQLWhere{Format: "b=:a", Patterns: map[string]interface{}{"b": 1}}, nil)
+
 	errRowFilter := NewMockRowFilter(mock)
 	errRowFilter.EXPECT().WherePattern().AnyTimes().
 		Return(nil, errors.New("mock error"))
@@ -309,6 +446,46 @@ func TestRowFilterAnd_WherePattern(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"with same pattern names",
+			RowFilterAnd{
+				SelectorFilter{"a": 123},
+				CompareFilter{Col: "a", Op: OpLt, Value: 456},
+				NotFilter{Filter: SelectorFilter{"a": 789}},
+			},
+			&SQLWhere{
+				Format:   "a=:a AND (a < :a_1) AND (NOT (a=:a_2))",
+				Patterns: map[string]interface{}{"a": 123, "a_1": 456, "a_2": 789},
+			},
+			false,
+		},
+		{
+			"with same like keys",
+			RowFilterAnd{LikeFilter{Key: "a", Value: "x%"}, LikeFilter{Key: "a", Value: "%y"}},
+			&SQLWhere{
+				Format:   "a like :a AND (a like :a_1)",
+				Patterns: map[string]interface{}{"a": "x%", "a_1": "%y"},
+			},
+			false,
+		},
+		{
+			"with conflicted reference",
+			RowFilterAnd{SelectorFilter{"a": 123}, refRowFilter},
+			nil,
+			true,
+		},
+		{
+			"with or element",
+			RowFilterAnd{
+				RowFilterOr{SelectorFilter{"a": 1}, NullFilter{Col: "a"}},
+				SelectorFilter{"b": 2},
+			},
+			&SQLWhere{
+				Format:   "((a=:a) OR (a IS NULL)) AND (b=:b)",
+				Patterns: map[string]interface{}{"a": 1, "b": 2},
+			},
+			false,
+		},
 		{
 			"with join elements",
 			RowFilterAnd{SelectorFilter{"a": 123}, joinRowFilter},
@@ -336,3 +513,58 @@ func TestRowFilterAnd_WherePattern(t *testing.T) {
 		})
 	}
 }
+
+func TestRowFilterOr_WherePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		f       RowFilterOr
+		want    *SQLWhere
+		wantErr bool
+	}{
+		{"empty list", nil, nil, false},
+		{
+			"one element",
+			RowFilterOr{SelectorFilter{"a": 123}},
+			&SQLWhere{Format: "((a=:a))", Patterns: map[string]interface{}{"a": 123}},
+			false,
+		},
+		{
+			"with nil elments",
+			RowFilterOr{SelectorFilter{"a": 123}, nil},
+			&SQLWhere{Format: "((a=:a))", Patterns: map[string]interface{}{"a": 123}},
+			false,
+		},
+		{
+			"with empty elments",
+			RowFilterOr{SelectorFilter{"a": 123}, SelectorFilter{}},
+			nil,
+			false,
+		},
+		{
+			"with same pattern names",
+			RowFilterOr{
+				BetweenFilter{Col: "a", From: 1, To: 2},
+				RowFilterAnd{InFilter{Col: "a", Values: []interface{}{3}}, InFilter{Col: "a", Values: []interface{}{4}}},
+			},
+			&SQLWhere{
+				Format:   "((a BETWEEN :aS AND :aE) OR (a IN (:a_0) AND (a IN (:a_0_1))))",
+				Patterns: map[string]interface{}{"aS": 1, "aE": 2, "a_0": 3, "a_0_1": 4},
+			},
+			false,
+		},
+		{"with error elements", RowFilterOr{CompareFilter{}}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.f.WherePattern()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RowFilterOr.WherePattern() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RowFilterOr.WherePattern() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package sqlm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// intTypeWidthRegex match the display width of integer types, such as: `INT(11)`.
+var intTypeWidthRegex = regexp.MustCompile(`^(TINYINT|SMALLINT|MEDIUMINT|INT|INTEGER|BIGINT)\(\d+\)`)
+
+// decimalZeroScaleRegex match the decimal types with zero scale, such as: `NUMERIC(20,0)`, it's same as `NUMERIC(20)`.
+var decimalZeroScaleRegex = regexp.MustCompile(`^(NUMERIC|DECIMAL)\((\d+),0\)`)
+
+// unsupportedMark the prefix of planned statements for the changes not supported by dialect.
+const unsupportedMark = "-- unsupported: "
+
+// MigrateOptions for Table#Migrate()
+type MigrateOptions struct {
+	DryRun    bool     // only return the planned statements without applying them.
+	Tables    []string // target tables to migrate, default is the table name, set them for split tables.
+	AllShards bool     // migrate all live tables got by Table#ShardTables() when Tables is empty.
+}
+
+// Migrate alter the live tables to match the row model: create the missing table, add the missing columns
+// and indexes, modify the columns whose type or nullability changed. Columns not in row model are kept.
+// The not null columns without default can not be added, because the existing rows can not be filled.
+// It returns the planned statements, which are applied in order when not dry run.
+func (t *Table) Migrate(options MigrateOptions) ([]string, error) {
+	return t.MigrateContext(context.Background(), options)
+}
+
+// MigrateContext migrate the live tables with context.
+func (t *Table) MigrateContext(ctx context.Context, options MigrateOptions) ([]string, error) {
+	d, err := t.dialect()
+	if err != nil {
+		return nil, err
+	}
+	dialect, ok := d.(InspectDialect)
+	if !ok {
+		return nil, fmt.Errorf("dialect of driver %s does not support inspecting live schema", t.Driver)
+	}
+	if !options.DryRun {
+		if err := t.Database.Create(); err != nil {
+			return nil, err
+		}
+	}
+
+	ext, err := t.executor()
+	if err != nil {
+		return nil, err
+	}
+
+	tables := options.Tables
+	if len(tables) == 0 && options.AllShards {
+		if tables, err = t.ShardTablesContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if len(tables) == 0 {
+		tables = []string{t.TableName}
+	}
+
+	var plan []string
+	for _, table := range tables {
+		cols, err := dialect.TableColumns(ctx, ext, table)
+		if err != nil {
+			return nil, fmt.Errorf("get columns of table %s failed: %w", table, err)
+		}
+
+		var indexes [][]string
+		if len(cols) > 0 {
+			if indexes, err = dialect.TableIndexes(ctx, ext, table); err != nil {
+				return nil, fmt.Errorf("get indexes of table %s failed: %w", table, err)
+			}
+		}
+
+		statements, err := t.getSchema().MigrateSQL(table, cols, indexes)
+		var errUnsupported *ErrorMigrateUnsupported
+		if err != nil && !(options.DryRun && errors.As(err, &errUnsupported)) {
+			return append(plan, statements...), err
+		}
+		plan = append(plan, statements...)
+	}
+
+	if options.DryRun || len(plan) == 0 {
+		return plan, nil
+	}
+
+	ddlExt, err := t.ddlExecutor()
+	if err != nil {
+		return plan, err
+	}
+	for _, statement := range plan {
+		if _, err := ddlExt.ExecContext(ctx, statement); err != nil {
+			return plan, fmt.Errorf("%w\n sql: %s", err, statement)
+		}
+	}
+
+	return plan, nil
+}
+
+// MigrateSQL return statements for migrating the live table to the schema, the live columns and indexes
+// are got by Dialect#TableColumns() and Dialect#TableIndexes(), the table is missing when liveCols is empty.
+// Changes not supported by the dialect are marked as comments in statements with ErrorMigrateUnsupported returned.
+func (t *TableSchema) MigrateSQL(targetTable string, liveCols []*ColSchema, liveIndexes [][]string) ([]string, error) {
+	dialect, err := GetDialect(t.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(liveCols) == 0 {
+		schema := *t
+		schema.Name = targetTable
+		return schema.createSQLs()
+	}
+
+	// the auto increment column is settled by dialect as in creating.
+	columns, _, err := t.settledColumns()
+	if err != nil {
+		return nil, err
+	}
+
+	liveColOfName := make(map[string]*ColSchema, len(liveCols))
+	for _, c := range liveCols {
+		liveColOfName[c.Name] = c
+	}
+
+	var statements []string
+	var unsupported []error
+	indexes, err := t.Indexes()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range columns {
+		if c.Unique {
+			indexes = append(indexes, IndexSchema{Name: c.Name, Cols: []string{c.Name}, Unique: true})
+		}
+
+		live, ok := liveColOfName[c.Name]
+		if !ok && c.NotNull && !c.AutoIncrement && (!c.Default || c.DefaultStr == "") {
+			// the existing rows can not be filled, it's rejected by sqlite, and postgresql when table not empty.
+			err := &ErrorSQLInvalid{Message: fmt.Sprintf(
+				"adding not null column %s without default to table %s is not supported", c.Name, targetTable)}
+			unsupported = append(unsupported, err)
+			statements = append(statements, unsupportedMark+err.Error())
+			continue
+		}
+		if !ok {
+			// keys are added as indexes.
+			col := *c
+			col.Primary = false
+			col.Unique = false
+			statements = append(statements,
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", t.quoteTable(targetTable), dialect.ColumnDDL(&col, false)))
+			continue
+		}
+
+		nullabilityChanged := !c.Primary && !c.AutoIncrement && c.NotNull != live.NotNull
+		if sameColType(dialect.ColumnType(c), live.Type) && !nullabilityChanged {
+			continue
+		}
+		inspector, ok := dialect.(InspectDialect)
+		if !ok {
+			return nil, fmt.Errorf("dialect of driver %s does not support modifying column", t.Driver)
+		}
+		modifies, err := inspector.ModifyColumnDDL(targetTable, c)
+		if err != nil {
+			unsupported = append(unsupported, err)
+			statements = append(statements, unsupportedMark+err.Error())
+			continue
+		}
+		statements = append(statements, modifies...)
+	}
+
+	for _, index := range indexes {
+		if hasIndex(liveIndexes, index.Cols) {
+			continue
+		}
+
+		ddl, inline := dialect.IndexDDL(targetTable, index.Name, index.Cols, index.Unique)
+		if inline {
+			ddl = fmt.Sprintf("ALTER TABLE %s ADD %s", t.quoteTable(targetTable), ddl)
+		}
+		statements = append(statements, ddl)
+	}
+
+	if len(unsupported) > 0 {
+		return statements, &ErrorMigrateUnsupported{Table: targetTable, Errs: unsupported}
+	}
+
+	return statements, nil
+}
+
+// sameColType report whether the column types are same, the case, spaces, display width of integer
+// and zero scale of decimal are ignored.
+func sameColType(a, b string) bool {
+	normalize := func(colType string) string {
+		colType = strings.Replace(strings.ToUpper(colType), " ", "", -1)
+		colType = intTypeWidthRegex.ReplaceAllString(colType, "$1")
+		colType = decimalZeroScaleRegex.ReplaceAllString(colType, "$1($2)")
+		if strings.HasPrefix(colType, "INTEGER") {
+			colType = "INT" + strings.TrimPrefix(colType, "INTEGER")
+		}
+
+		return colType
+	}
+
+	return normalize(a) == normalize(b)
+}
+
+// hasIndex report whether the index on the columns exists.
+func hasIndex(indexes [][]string, cols []string) bool {
+	for _, index := range indexes {
+		if reflect.DeepEqual(index, cols) {
+			return true
+		}
+	}
+
+	return false
+}
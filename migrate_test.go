@@ -0,0 +1,326 @@
+package sqlm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testSimpleRecordV1 the early version of testSimpleRecord.
+type testSimpleRecordV1 struct {
+	ID   int64  `db:"id,type=INTEGER,auto_increment"`
+	Name string `db:"name,type=VARCHAR(32),not_null"`
+}
+
+// testSimpleRecordV2 the version of testSimpleRecord with name column type changed.
+type testSimpleRecordV2 struct {
+	ID   int64  `db:"id,type=INTEGER,auto_increment"`
+	Name string `db:"name,type=VARCHAR(64),not_null"`
+}
+
+func TestTableSchema_MigrateSQL(t *testing.T) {
+	columns := func() []*ColSchema {
+		return []*ColSchema{
+			{Name: "id", Type: "BIGINT", AutoIncrement: true},
+			{Name: "a", Type: "VARCHAR(32)", Primary: true},
+			{Name: "b", Type: "INT", NotNull: true, Default: true, DefaultStr: "0"},
+			{Name: "c", Type: "VARCHAR(64)", Unique: true},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		driver      string
+		liveCols    []*ColSchema
+		liveIndexes [][]string
+		want        []string
+		wantErr     bool
+	}{
+		{
+			"mysql - table missing",
+			DriverMysql,
+			nil,
+			nil,
+			[]string{"CREATE TABLE IF NOT EXISTS `test_1` (\n" +
+				"`id` BIGINT NOT NULL AUTO_INCREMENT,\n" +
+				"`a` VARCHAR(32) PRIMARY KEY,\n" +
+				"`b` INT NOT NULL DEFAULT 0,\n" +
+				"`c` VARCHAR(64) UNIQUE KEY,\n" +
+				"KEY `id` (`id`)\n)"},
+			false,
+		},
+		{
+			"mysql - up to date",
+			DriverMysql,
+			[]*ColSchema{
+				{Name: "id", Type: "bigint(20)", NotNull: true},
+				{Name: "a", Type: "varchar(32)", NotNull: true, Primary: true},
+				{Name: "b", Type: "int(11)", NotNull: true},
+				{Name: "c", Type: "varchar(64)"},
+				{Name: "d", Type: "text"},
+			},
+			[][]string{{"a"}, {"c"}, {"id"}},
+			nil,
+			false,
+		},
+		{
+			"mysql - add and modify",
+			DriverMysql,
+			[]*ColSchema{
+				{Name: "id", Type: "bigint(20)", NotNull: true},
+				{Name: "a", Type: "varchar(32)", NotNull: true, Primary: true},
+				{Name: "b", Type: "varchar(8)"},
+			},
+			[][]string{{"a"}},
+			[]string{
+				"ALTER TABLE `test_1` MODIFY COLUMN `b` INT NOT NULL DEFAULT 0",
+				"ALTER TABLE `test_1` ADD COLUMN `c` VARCHAR(64)",
+				"ALTER TABLE `test_1` ADD KEY `id` (`id`)",
+				"ALTER TABLE `test_1` ADD UNIQUE KEY `c` (`c`)",
+			},
+			false,
+		},
+		{
+			"postgres - add and modify",
+			DriverPostgres,
+			[]*ColSchema{
+				{Name: "id", Type: "BIGINT", NotNull: true},
+				{Name: "a", Type: "VARCHAR(32)", NotNull: true},
+				{Name: "b", Type: "INTEGER"},
+			},
+			[][]string{{"a"}, {"id"}},
+			[]string{
+				`ALTER TABLE "test_1" ALTER COLUMN "b" TYPE INTEGER USING "b"::INTEGER`,
+				`ALTER TABLE "test_1" ALTER COLUMN "b" SET NOT NULL`,
+				`ALTER TABLE "test_1" ADD COLUMN "c" VARCHAR(64)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "test_1_c" ON "test_1" ("c")`,
+			},
+			false,
+		},
+		{
+			"sqlite - auto increment with other primary",
+			DriverSQLite3,
+			[]*ColSchema{
+				{Name: "a", Type: "VARCHAR(32)", NotNull: true},
+				{Name: "b", Type: "TEXT", NotNull: true},
+			},
+			nil,
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &TableSchema{Driver: tt.driver, Name: "test", Columns: columns()}
+			got, err := s.MigrateSQL("test_1", tt.liveCols, tt.liveIndexes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TableSchema.MigrateSQL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TableSchema.MigrateSQL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableSchema_MigrateSQL_unsupported(t *testing.T) {
+	s := &TableSchema{Driver: DriverSQLite3, Name: "test", Columns: []*ColSchema{
+		{Name: "id", Type: "INTEGER", Primary: true},
+		{Name: "a", Type: "INT", NotNull: true},
+		{Name: "b", Type: "VARCHAR(64)"},
+		{Name: "c", Type: "INT", NotNull: true},
+		{Name: "d", Type: "INT", NotNull: true, Default: true, DefaultStr: "0"},
+	}}
+	liveCols := []*ColSchema{{Name: "id", Type: "INTEGER"}, {Name: "a", Type: "TEXT", NotNull: true}}
+
+	got, err := s.MigrateSQL("test_1", liveCols, nil)
+	var errUnsupported *ErrorMigrateUnsupported
+	if !errors.As(err, &errUnsupported) || errUnsupported.Table != "test_1" {
+		t.Errorf("TableSchema.MigrateSQL() error = %v, want ErrorMigrateUnsupported of table test_1", err)
+	}
+	want := []string{
+		unsupportedMark + "sqlite not support modifying column a of table test_1",
+		`ALTER TABLE "test_1" ADD COLUMN "b" VARCHAR(64)`,
+		unsupportedMark + "adding not null column c without default to table test_1 is not supported",
+		`ALTER TABLE "test_1" ADD COLUMN "d" INT NOT NULL DEFAULT 0`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TableSchema.MigrateSQL() = %q, want %q", got, want)
+	}
+}
+
+func TestInspectDialect_ModifyColumnDDL(t *testing.T) {
+	c := &ColSchema{Name: "b", Type: "INT", NotNull: true}
+	tests := []struct {
+		name string
+		d    InspectDialect
+		want []string
+	}{
+		{"mysql", new(MySQLDialect), []string{"ALTER TABLE `s`.`t` MODIFY COLUMN `b` INT NOT NULL"}},
+		{"postgres", new(PostgresDialect), []string{
+			`ALTER TABLE "s"."t" ALTER COLUMN "b" TYPE INTEGER USING "b"::INTEGER`,
+			`ALTER TABLE "s"."t" ALTER COLUMN "b" SET NOT NULL`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.d.ModifyColumnDDL("s.t", c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InspectDialect.ModifyColumnDDL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sameColType(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"int(11)", "INT", true},
+		{"INTEGER", "int", true},
+		{"bigint(20) unsigned", "BIGINT UNSIGNED", true},
+		{"varchar(32)", "VARCHAR(32)", true},
+		{"varchar(32)", "VARCHAR(64)", false},
+		{"DOUBLE PRECISION", "double precision", true},
+		{"tinyint(4)", "INT", false},
+		{"NUMERIC(20)", "NUMERIC(20,0)", true},
+		{"decimal(10,2)", "DECIMAL(10, 2)", true},
+		{"NUMERIC(20)", "NUMERIC(10,0)", false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s-%s", tt.a, tt.b), func(t *testing.T) {
+			if got := sameColType(tt.a, tt.b); got != tt.want {
+				t.Errorf("sameColType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTable_Migrate(t *testing.T) {
+	old := newTestSQLiteTable(t, "test_migrate", &testSimpleRecordV1{})
+	if _, err := old.Insert(&testSimpleRecordV1{ID: 1, Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("type changed", func(t *testing.T) {
+		changed := &Table{Database: old.Database, TableName: "test_migrate"}
+		changed.SetRowModel(func() interface{} { return &testSimpleRecordV2{} })
+
+		// sqlite 不支持修改列，dry run 时在计划中标记
+		want := []string{unsupportedMark + "sqlite not support modifying column name of table test_migrate"}
+		plan, err := changed.Migrate(MigrateOptions{DryRun: true})
+		if err != nil || !reflect.DeepEqual(plan, want) {
+			t.Errorf("Table.Migrate() dry run = %q, %v, want %q", plan, err, want)
+		}
+
+		var errUnsupported *ErrorMigrateUnsupported
+		var errInvalid *ErrorSQLInvalid
+		if _, err := changed.Migrate(MigrateOptions{}); !errors.As(err, &errUnsupported) || !errors.As(err, &errInvalid) {
+			t.Errorf("Table.Migrate() error = %v, want ErrorMigrateUnsupported", err)
+		}
+	})
+
+	table := &Table{Database: old.Database, TableName: "test_migrate"}
+	table.SetRowModel(func() interface{} { return &testSimpleRecord{} })
+
+	wantPlan := []string{
+		`ALTER TABLE "test_migrate" ADD COLUMN "score" INT DEFAULT 0`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS "test_migrate_name" ON "test_migrate" ("name")`,
+		`CREATE TABLE IF NOT EXISTS "test_migrate_1" (` + "\n" +
+			`"id" INTEGER PRIMARY KEY,` + "\n" +
+			`"name" VARCHAR(32) NOT NULL UNIQUE,` + "\n" +
+			`"score" INT DEFAULT 0` + "\n)",
+	}
+	options := MigrateOptions{Tables: []string{"test_migrate", "test_migrate_1"}}
+
+	dryRunOptions := options
+	dryRunOptions.DryRun = true
+	plan, err := table.Migrate(dryRunOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(plan, wantPlan) {
+		t.Errorf("Table.Migrate() dry run = %q, want %q", plan, wantPlan)
+	}
+	if plan, _ := table.Migrate(dryRunOptions); !reflect.DeepEqual(plan, wantPlan) {
+		t.Errorf("Table.Migrate() should not apply the plan in dry run, planned again: %q", plan)
+	}
+
+	plan, err = table.Migrate(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(plan, wantPlan) {
+		t.Errorf("Table.Migrate() = %q, want %q", plan, wantPlan)
+	}
+
+	if _, err := table.Insert(&testSimpleRecord{ID: 2, Name: "b", Score: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.Insert(&testSimpleRecord{ID: 3, Name: "b"}); err == nil || !strings.Contains(err.Error(), "UNIQUE") {
+		t.Errorf("Table.Insert() error = %v, want unique constraint failed", err)
+	}
+	count, err := table.Count(CompareFilter{Col: "score", Op: OpGe, Value: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("Table.Count() after migrating = %v, want 2", count)
+	}
+
+	plan, err = table.Migrate(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 0 {
+		t.Errorf("Table.Migrate() for migrated tables = %q, want empty", plan)
+	}
+}
+
+// testIndexRecordV1 the version of testIndexRecord without indexes.
+type testIndexRecordV1 struct {
+	ID        int64  `db:"id,type=BIGINT,primary"`
+	ProjectID int32  `db:"projectId,type=INT"`
+	Name      string `db:"name,type=VARCHAR(32)"`
+	Time      int64  `db:"time,type=BIGINT"`
+}
+
+func TestTable_Migrate_indexes(t *testing.T) {
+	old := newTestSQLiteTable(t, "test_index", &testIndexRecordV1{})
+
+	table := &Table{Database: old.Database, TableName: old.TableName}
+	table.SetRowModel(func() interface{} { return &testIndexRecord{} })
+
+	plan, err := table.Migrate(MigrateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`CREATE INDEX IF NOT EXISTS "test_index_idx_proj_time" ON "test_index" ("projectId","time")`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS "test_index_uk_proj_name" ON "test_index" ("projectId","name")`,
+		`CREATE INDEX IF NOT EXISTS "test_index_idx_time" ON "test_index" ("time")`,
+	}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("Table.Migrate() = %q, want %q", plan, want)
+	}
+
+	if plan, err := table.Migrate(MigrateOptions{}); err != nil || len(plan) != 0 {
+		t.Errorf("Table.Migrate() for migrated table = %q, %v, want empty", plan, err)
+	}
+
+	if _, err := table.Insert(&testIndexRecord{ID: 1, ProjectID: 1, Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.Insert(&testIndexRecord{ID: 2, ProjectID: 2, Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.Insert(&testIndexRecord{ID: 3, ProjectID: 1, Name: "a"}); err == nil || !strings.Contains(err.Error(), "UNIQUE") {
+		t.Errorf("Table.Insert() error = %v, want unique constraint failed", err)
+	}
+}
@@ -0,0 +1,499 @@
+package sqlm
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// MigrationsTableName default name of the table recording applied migrations.
+	MigrationsTableName = "sqlm_migrations"
+
+	migrationLockID               = 1                // id of the only lock row.
+	defaultMigrationLockTTL       = 10 * time.Minute // the lock older than it is treated as stale.
+	defaultMigrationRetryInterval = 500 * time.Millisecond
+)
+
+// MigrationFunc migration implemented in go, it runs in the transaction.
+type MigrationFunc func(ctx context.Context, tx *Tx) error
+
+// Migration one versioned migration, it's implemented by sql statements or go funcs.
+// Sql statements are separated by `;\n`.
+type Migration struct {
+	Version  int64
+	Name     string
+	UpSQL    string
+	DownSQL  string
+	UpFunc   MigrationFunc
+	DownFunc MigrationFunc
+	// FuncRevision revision of the go func migration set by caller, change it when UpFunc changed.
+	FuncRevision string
+}
+
+// Checksum return the checksum of up sql, or the name and revision for the go func migration,
+// so the change of go func is detected only when FuncRevision changed.
+func (m *Migration) Checksum() string {
+	content := m.UpSQL
+	if m.UpFunc != nil {
+		content = "func:" + m.Name
+		if m.FuncRevision != "" {
+			content += ":" + m.FuncRevision
+		}
+	}
+	sum := sha256.Sum256([]byte(content))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// MigrationRecord applied migration in history table.
+type MigrationRecord struct {
+	Version    int64     `json:"version"    db:"version,type=BIGINT,primary"`
+	Name       string    `json:"name"       db:"name,type=VARCHAR(255),not_null"`
+	Checksum   string    `json:"checksum"   db:"checksum,type=VARCHAR(64),not_null"`
+	AppliedAt  time.Time `json:"appliedAt"  db:"applied_at,type=DATETIME,not_null"`
+	DurationMS int64     `json:"durationMs" db:"duration_ms,type=BIGINT,not_null"`
+}
+
+// MigrationStatus status of registered migration.
+type MigrationStatus struct {
+	Version          int64
+	Name             string
+	Applied          bool
+	AppliedAt        time.Time
+	Duration         time.Duration
+	ChecksumMismatch bool // the applied migration has been changed.
+}
+
+// migrationLock the lock row, only one migrator can insert it. The times are set by CURRENT_TIMESTAMP
+// of database: LockedAt is refreshed by the holder periodically, CheckedAt is set by the waiters
+// for comparing with LockedAt, so the clocks of migrators are not involved.
+type migrationLock struct {
+	ID        int64     `db:"id,type=INT,primary"`
+	Owner     string    `db:"owner,type=VARCHAR(64),not_null"`
+	LockedAt  time.Time `db:"locked_at,type=DATETIME,not_null"`
+	CheckedAt time.Time `db:"checked_at,type=DATETIME,not_null"`
+}
+
+// Migrator run versioned migrations registered against the database,
+// the applied ones are recorded in history table, and migrations are run under a lock.
+type Migrator struct {
+	Database      *Database
+	TableName     string        // history table name, default is MigrationsTableName, lock table is suffixed by `_lock`.
+	LockTTL       time.Duration // the lock not refreshed longer than it is treated as stale, default is 10 minutes.
+	RetryInterval time.Duration // interval for retrying to acquire the lock, default is 500ms.
+
+	migrations []Migration
+}
+
+// NewMigrator return migrator for database.
+func NewMigrator(db *Database, migrations ...Migration) (*Migrator, error) {
+	m := &Migrator{Database: db}
+	if err := m.Register(migrations...); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Register add migrations, versions should be positive and unique.
+func (m *Migrator) Register(migrations ...Migration) error {
+	for _, migration := range migrations {
+		if migration.Version <= 0 {
+			return fmt.Errorf("invalid migration version: %d", migration.Version)
+		}
+		if migration.UpSQL == "" && migration.UpFunc == nil {
+			return fmt.Errorf("migration %d has no up sql or func", migration.Version)
+		}
+		if _, dup := m.migration(migration.Version); dup {
+			return fmt.Errorf("migration %d registered twice", migration.Version)
+		}
+
+		m.migrations = append(m.migrations, migration)
+	}
+
+	sort.Slice(m.migrations, func(i, j int) bool { return m.migrations[i].Version < m.migrations[j].Version })
+	return nil
+}
+
+// Up apply all pending migrations in version order, return the versions applied.
+// Each migration and its history record are committed in one transaction, but mysql commits DDL implicitly.
+func (m *Migrator) Up(ctx context.Context) ([]int64, error) {
+	var applied []int64
+	err := m.withLock(ctx, func(ctx context.Context) error {
+		records, err := m.appliedRecords(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			record, ok := records[migration.Version]
+			if ok {
+				if record.Checksum != migration.Checksum() {
+					return fmt.Errorf("checksum of applied migration %d mismatched", migration.Version)
+				}
+				continue
+			}
+
+			if err := m.apply(ctx, migration); err != nil {
+				return err
+			}
+			applied = append(applied, migration.Version)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down revert the applied migrations with version greater than `to` in reverse order, return the versions reverted.
+func (m *Migrator) Down(ctx context.Context, to int64) ([]int64, error) {
+	var reverted []int64
+	err := m.withLock(ctx, func(ctx context.Context) error {
+		records, err := m.appliedRecords(ctx)
+		if err != nil {
+			return err
+		}
+
+		var versions []int64
+		for v := range records {
+			if v > to {
+				versions = append(versions, v)
+			}
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		for _, v := range versions {
+			migration, ok := m.migration(v)
+			if !ok {
+				return fmt.Errorf("applied migration %d is not registered", v)
+			}
+			if err := m.revert(ctx, migration); err != nil {
+				return err
+			}
+			reverted = append(reverted, v)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status return status of registered migrations in version order.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	records, err := m.appliedRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := records[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.AppliedAt
+			status.Duration = time.Duration(record.DurationMS) * time.Millisecond
+			status.ChecksumMismatch = record.Checksum != migration.Checksum()
+		}
+		ret = append(ret, status)
+	}
+
+	return ret, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, migration Migration) error {
+	start := time.Now()
+	err := m.Database.WithTx(ctx, func(tx *Tx) error {
+		if err := runMigration(ctx, tx, migration.UpSQL, migration.UpFunc); err != nil {
+			return err
+		}
+
+		record := &MigrationRecord{
+			Version:    migration.Version,
+			Name:       migration.Name,
+			Checksum:   migration.Checksum(),
+			AppliedAt:  start,
+			DurationMS: time.Since(start).Milliseconds(),
+		}
+		_, err := tx.Table(m.historyTable()).InsertContext(ctx, record)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("apply migration %d failed: %w", migration.Version, err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, migration Migration) error {
+	if migration.DownSQL == "" && migration.DownFunc == nil {
+		return fmt.Errorf("migration %d has no down sql or func", migration.Version)
+	}
+
+	err := m.Database.WithTx(ctx, func(tx *Tx) error {
+		if err := runMigration(ctx, tx, migration.DownSQL, migration.DownFunc); err != nil {
+			return err
+		}
+
+		return tx.Table(m.historyTable()).DeleteContext(ctx, SelectorFilter{"version": migration.Version})
+	})
+	if err != nil {
+		return fmt.Errorf("revert migration %d failed: %w", migration.Version, err)
+	}
+
+	return nil
+}
+
+// runMigration run the go func, or the sql statements one by one.
+func runMigration(ctx context.Context, tx *Tx, statements string, fn MigrationFunc) error {
+	if fn != nil {
+		return fn(ctx, tx)
+	}
+
+	for _, statement := range strings.Split(statements, sqlStatementSep) {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+		if _, err := tx.tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("%w\n sql: %s", err, statement)
+		}
+	}
+
+	return nil
+}
+
+// appliedRecords return the applied migrations keyed by version, the history table is created when not exists.
+func (m *Migrator) appliedRecords(ctx context.Context) (map[int64]*MigrationRecord, error) {
+	history := m.historyTable()
+	if err := history.CreateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	records, err := history.ListContext(ctx, nil, ListOptions{OrderByColumn: "version"})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[int64]*MigrationRecord, len(records))
+	for _, r := range records {
+		record := r.(*MigrationRecord)
+		ret[record.Version] = record
+	}
+
+	return ret, nil
+}
+
+// withLock run fn when the lock acquired, it waits until the lock released by others or ctx done.
+// The lock is refreshed periodically while fn running, the ctx of fn is canceled when the lock is lost.
+func (m *Migrator) withLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	lockTable := m.lockTable()
+	if err := lockTable.CreateContext(ctx); err != nil {
+		return err
+	}
+
+	owner, err := newLockOwner()
+	if err != nil {
+		return err
+	}
+
+	for {
+		insertErr := m.execLock(ctx, "INSERT INTO %[1]s (%[2]s,%[3]s,%[4]s,%[5]s) "+
+			"VALUES (:id,:owner,CURRENT_TIMESTAMP,CURRENT_TIMESTAMP)", owner)
+		if insertErr == nil {
+			break
+		}
+		if !m.isDupKey(insertErr) {
+			return fmt.Errorf("acquire migration lock failed: %w", insertErr)
+		}
+
+		holder := "others"
+		held, err := m.checkLock(ctx)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			// released by others just now.
+		case err != nil:
+			return fmt.Errorf("check migration lock failed: %w", err)
+		case held.CheckedAt.Sub(held.LockedAt) > m.lockTTL():
+			// 持有者异常退出, 清理过期的锁
+			if err := lockTable.DeleteContext(ctx, SelectorFilter{"id": migrationLockID, "owner": held.Owner}); err != nil {
+				return err
+			}
+		default:
+			holder = held.Owner
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for migration lock held by %s failed: %w", holder, ctx.Err())
+		case <-time.After(m.retryInterval()):
+		}
+	}
+
+	fnCtx, cancel := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		m.refreshLock(fnCtx, cancel, owner)
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+		_ = lockTable.DeleteContext(context.Background(), SelectorFilter{"id": migrationLockID, "owner": owner})
+	}()
+
+	return fn(fnCtx)
+}
+
+// refreshLock refresh the lock held by owner until ctx done, cancel is called when the lock is lost,
+// or no refreshing succeeded in the lock TTL, because the lock may be taken over as stale by others.
+func (m *Migrator) refreshLock(ctx context.Context, cancel context.CancelFunc, owner string) {
+	ticker := time.NewTicker(m.lockTTL() / 3)
+	defer ticker.Stop()
+
+	refreshedAt := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := m.execLock(ctx, "UPDATE %[1]s SET %[4]s=CURRENT_TIMESTAMP WHERE %[2]s=:id AND %[3]s=:owner", owner)
+		if errors.Is(err, sql.ErrNoRows) {
+			// mysql reports no rows affected when the time is not changed in the same second.
+			err = m.lockTable().GetContext(ctx, SelectorFilter{"id": migrationLockID, "owner": owner}, &migrationLock{})
+			if errors.Is(err, sql.ErrNoRows) {
+				cancel()
+				return
+			}
+		}
+
+		// the failed refreshing is retried in next tick, the lock is kept before it's stale.
+		if err == nil {
+			refreshedAt = time.Now()
+		} else if time.Since(refreshedAt) >= m.lockTTL() {
+			cancel()
+			return
+		}
+	}
+}
+
+// checkLock set the checked time of lock and return it, sql.ErrNoRows is returned when lock not exists.
+func (m *Migrator) checkLock(ctx context.Context) (*migrationLock, error) {
+	// no rows affected is not reliable for the existence, the time may be not changed in the same second.
+	err := m.execLock(ctx, "UPDATE %[1]s SET %[5]s=CURRENT_TIMESTAMP WHERE %[2]s=:id", "")
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	var held migrationLock
+	if err := m.lockTable().GetContext(ctx, SelectorFilter{"id": migrationLockID}, &held); err != nil {
+		return nil, err
+	}
+
+	return &held, nil
+}
+
+// execLock exec the statement on lock table, the format is applied with quoted table name
+// and columns id, owner, locked_at, checked_at. sql.ErrNoRows is returned when no rows affected.
+func (m *Migrator) execLock(ctx context.Context, format string, owner string) error {
+	lockTable := m.lockTable()
+	schema := lockTable.getSchema()
+	query := fmt.Sprintf(format, schema.quoteTable(lockTable.TableName),
+		schema.quote("id"), schema.quote("owner"), schema.quote("locked_at"), schema.quote("checked_at"))
+
+	ext, err := lockTable.executor()
+	if err != nil {
+		return err
+	}
+	ret, err := lockTable.namedExec(ctx, ext, query, map[string]interface{}{"id": migrationLockID, "owner": owner})
+	if err != nil {
+		return err
+	}
+	if affected, err := ret.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// isDupKey report whether the error is caused by the lock held by others, it's assumed when
+// the dialect can not recognize errors of duplicate keys.
+func (m *Migrator) isDupKey(err error) bool {
+	d, dErr := GetDialect(m.Database.Driver)
+	if dErr != nil {
+		return false
+	}
+	if dupKey, ok := d.(DupKeyDialect); ok {
+		return dupKey.IsDupKey(err)
+	}
+
+	return true
+}
+
+func (m *Migrator) migration(version int64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+
+	return Migration{}, false
+}
+
+func (m *Migrator) historyTable() *Table {
+	table := &Table{Database: m.Database, TableName: m.tableName()}
+	table.SetRowModel(func() interface{} { return &MigrationRecord{} })
+
+	return table
+}
+
+func (m *Migrator) lockTable() *Table {
+	table := &Table{Database: m.Database, TableName: m.tableName() + "_lock"}
+	table.SetRowModel(func() interface{} { return &migrationLock{} })
+
+	return table
+}
+
+func (m *Migrator) tableName() string {
+	if m.TableName != "" {
+		return m.TableName
+	}
+
+	return MigrationsTableName
+}
+
+func (m *Migrator) lockTTL() time.Duration {
+	if m.LockTTL > 0 {
+		return m.LockTTL
+	}
+
+	return defaultMigrationLockTTL
+}
+
+func (m *Migrator) retryInterval() time.Duration {
+	if m.RetryInterval > 0 {
+		return m.RetryInterval
+	}
+
+	return defaultMigrationRetryInterval
+}
+
+// newLockOwner return random owner id of lock.
+func newLockOwner() (string, error) {
+	bs := make([]byte, 8)
+	if _, err := rand.Read(bs); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bs), nil
+}
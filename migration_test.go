@@ -0,0 +1,309 @@
+package sqlm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestMigrator(t *testing.T, migrations ...Migration) *Migrator {
+	db := &Database{Driver: DriverSQLite3, DSN: fmt.Sprintf("file:%s/migration.db", t.TempDir())}
+	m, err := NewMigrator(db, migrations...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+// testExec exec the statement on database.
+func testExec(t *testing.T, db *Database, query string) error {
+	con, err := db.Con()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = con.Exec(query)
+
+	return err
+}
+
+func testMigrations() []Migration {
+	return []Migration{
+		{
+			Version: 2,
+			Name:    "add score",
+			UpSQL:   "ALTER TABLE test_migration ADD COLUMN score INT DEFAULT 0",
+			DownFunc: func(ctx context.Context, tx *Tx) error {
+				// sqlite 3.24 不支持删除列, 重建表
+				for _, s := range []string{
+					"CREATE TABLE test_migration_1 (id INTEGER PRIMARY KEY, name VARCHAR(32))",
+					"INSERT INTO test_migration_1 SELECT id, name FROM test_migration",
+					"DROP TABLE test_migration",
+					"ALTER TABLE test_migration_1 RENAME TO test_migration",
+				} {
+					if _, err := tx.Tx().ExecContext(ctx, s); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version: 1,
+			Name:    "create table",
+			UpSQL: "CREATE TABLE test_migration (id INTEGER PRIMARY KEY, name VARCHAR(32));\n" +
+				"INSERT INTO test_migration (id, name) VALUES (1, 'a')",
+			DownSQL: "DROP TABLE test_migration",
+		},
+	}
+}
+
+func TestNewMigrator(t *testing.T) {
+	tests := []struct {
+		name       string
+		migrations []Migration
+		wantErr    bool
+	}{
+		{"ok", testMigrations(), false},
+		{"invalid version", []Migration{{Version: 0, UpSQL: "SELECT 1"}}, true},
+		{"no up", []Migration{{Version: 1}}, true},
+		{"duplicated", []Migration{{Version: 1, UpSQL: "SELECT 1"}, {Version: 1, UpSQL: "SELECT 2"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewMigrator(nil, tt.migrations...); (err != nil) != tt.wantErr {
+				t.Errorf("NewMigrator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMigrator(t, testMigrations()...)
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1, 2}; !reflect.DeepEqual(applied, want) {
+		t.Errorf("Migrator.Up() = %v, want %v", applied, want)
+	}
+	if applied, err := m.Up(ctx); err != nil || len(applied) != 0 {
+		t.Errorf("Migrator.Up() again = %v, %v, want nothing applied", applied, err)
+	}
+
+	if err := testExec(t, m.Database, "UPDATE test_migration SET score = 3 WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("Migrator.Status() = %v, want 2 statuses", statuses)
+	}
+	for i, s := range statuses {
+		if s.Version != int64(i+1) || !s.Applied || s.AppliedAt.IsZero() || s.ChecksumMismatch {
+			t.Errorf("Migrator.Status()[%d] = %+v, want applied", i, s)
+		}
+	}
+
+	reverted, err := m.Down(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{2}; !reflect.DeepEqual(reverted, want) {
+		t.Errorf("Migrator.Down() = %v, want %v", reverted, want)
+	}
+	if err := testExec(t, m.Database, "UPDATE test_migration SET score = 3 WHERE id = 1"); err == nil {
+		t.Errorf("column score should be dropped after reverting")
+	}
+
+	statuses, err = m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !statuses[0].Applied || statuses[1].Applied {
+		t.Errorf("Migrator.Status() after reverting = %+v", statuses)
+	}
+
+	reverted, err = m.Down(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1}; !reflect.DeepEqual(reverted, want) {
+		t.Errorf("Migrator.Down() = %v, want %v", reverted, want)
+	}
+	if applied, err := m.Up(ctx); err != nil || !reflect.DeepEqual(applied, []int64{1, 2}) {
+		t.Errorf("Migrator.Up() after reverting all = %v, %v", applied, err)
+	}
+}
+
+func TestMigrator_Up_failed(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMigrator(t, Migration{Version: 1, Name: "broken", UpSQL: "CREATE TABLE test_broken (id INT);\nBROKEN"})
+
+	if _, err := m.Up(ctx); err == nil {
+		t.Fatal("Migrator.Up() should fail for broken sql")
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses[0].Applied {
+		t.Errorf("Migrator.Status() = %+v, failed migration should not be recorded", statuses)
+	}
+	if err := testExec(t, m.Database, "SELECT * FROM test_broken"); err == nil {
+		t.Errorf("failed migration should be rolled back")
+	}
+}
+
+func TestMigrator_checksumMismatch(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMigrator(t, Migration{Version: 1, UpSQL: "CREATE TABLE test_checksum (id INT)"})
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := &Migrator{Database: m.Database}
+	if err := changed.Register(Migration{Version: 1, UpSQL: "CREATE TABLE test_checksum (id BIGINT)"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := changed.Up(ctx); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Migrator.Up() error = %v, want checksum mismatched", err)
+	}
+
+	statuses, err := changed.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !statuses[0].ChecksumMismatch {
+		t.Errorf("Migrator.Status() = %+v, want checksum mismatch", statuses)
+	}
+}
+
+func TestMigrator_lock(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMigrator(t, Migration{Version: 1, UpSQL: "CREATE TABLE test_lock (id INT)"})
+	m.RetryInterval = 10 * time.Millisecond
+
+	lockTable := m.lockTable()
+	if err := lockTable.Create(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lockTable.Insert(&migrationLock{ID: migrationLockID, Owner: "other", LockedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("held by other", func(t *testing.T) {
+		timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+
+		if _, err := m.Up(timeoutCtx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Migrator.Up() error = %v, want deadline exceeded", err)
+		}
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		// the lock is not refreshed for an hour by the clock of database.
+		if err := testExec(t, m.Database, "UPDATE sqlm_migrations_lock SET locked_at = datetime('now', '-1 hour')"); err != nil {
+			t.Fatal(err)
+		}
+
+		if applied, err := m.Up(ctx); err != nil || !reflect.DeepEqual(applied, []int64{1}) {
+			t.Errorf("Migrator.Up() = %v, %v, want stale lock taken over", applied, err)
+		}
+		if exists, err := lockTable.Exists(nil); err != nil || exists {
+			t.Errorf("lock should be released, exists = %v, err = %v", exists, err)
+		}
+	})
+}
+
+func TestMigrator_lockRefreshed(t *testing.T) {
+	var lockedAt []time.Time
+	var m *Migrator
+	m = newTestMigrator(t, Migration{Version: 1, Name: "slow", UpFunc: func(ctx context.Context, _ *Tx) error {
+		for i := 0; i < 2; i++ {
+			var held migrationLock
+			if err := m.lockTable().GetContext(ctx, SelectorFilter{"id": migrationLockID}, &held); err != nil {
+				return err
+			}
+			lockedAt = append(lockedAt, held.LockedAt)
+			// the time of sqlite is in seconds.
+			time.Sleep(1200 * time.Millisecond)
+		}
+		return nil
+	}})
+	m.LockTTL = 300 * time.Millisecond
+
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(lockedAt) != 2 || !lockedAt[1].After(lockedAt[0]) {
+		t.Errorf("lock times = %v, want refreshed while migrating", lockedAt)
+	}
+}
+
+func TestMigrator_lockInsertFailed(t *testing.T) {
+	m := newTestMigrator(t, Migration{Version: 1, UpSQL: "CREATE TABLE test_lock_failed (id INT)"})
+	m.RetryInterval = 10 * time.Millisecond
+	// the lock table of other schema, inserting fails without duplicate keys.
+	if err := testExec(t, m.Database, "CREATE TABLE sqlm_migrations_lock (id INT PRIMARY KEY)"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := m.Up(ctx); err == nil || errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Migrator.Up() error = %v, want inserting lock failed without waiting", err)
+	}
+}
+
+func TestMigrator_lockRefreshFailed(t *testing.T) {
+	var m *Migrator
+	m = newTestMigrator(t, Migration{Version: 1, Name: "slow", UpFunc: func(ctx context.Context, _ *Tx) error {
+		// refreshing fails since the lock table is dropped.
+		if err := testExec(t, m.Database, "DROP TABLE sqlm_migrations_lock"); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(3 * time.Second):
+			return nil
+		}
+	}})
+	m.LockTTL = 300 * time.Millisecond
+
+	if _, err := m.Up(context.Background()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Migrator.Up() error = %v, want canceled when lock not refreshed", err)
+	}
+}
+
+func TestMigration_Checksum(t *testing.T) {
+	up := func(context.Context, *Tx) error { return nil }
+	tests := []struct {
+		name string
+		a, b Migration
+		want bool
+	}{
+		{"sql changed", Migration{UpSQL: "a"}, Migration{UpSQL: "b"}, false},
+		{"func without revision", Migration{Name: "f", UpFunc: up}, Migration{Name: "f", UpFunc: up}, true},
+		{"func revision changed", Migration{Name: "f", UpFunc: up, FuncRevision: "1"},
+			Migration{Name: "f", UpFunc: up, FuncRevision: "2"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Checksum() == tt.b.Checksum(); got != tt.want {
+				t.Errorf("Migration.Checksum() equal = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
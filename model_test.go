@@ -31,3 +31,18 @@ type testRecord struct {
 	Title        string     `json:"title,omitempty"        db:"title,type=VARCHAR(128),not_null"`
 	Body         string     `json:"body,omitempty"         db:"body,type=VARCHAR(1024),not_null"`
 }
+
+// testSimpleRecord record for unit testing with sqlite backend
+type testSimpleRecord struct {
+	ID    int64  `json:"id,omitempty"    db:"id,type=INTEGER,auto_increment"`
+	Name  string `json:"name,omitempty"  db:"name,type=VARCHAR(32),not_null,unique"`
+	Score int32  `json:"score,omitempty" db:"score,type=INT,default=0"`
+}
+
+// testKeywordRecord record with columns named by sql keywords.
+type testKeywordRecord struct {
+	ID    int64  `json:"id,omitempty"    db:"id,type=INTEGER,auto_increment"`
+	Order int32  `json:"order,omitempty" db:"order,type=INT,default=0"`
+	Key   string `json:"key,omitempty"   db:"key,type=VARCHAR(32)"`
+	Group string `json:"group,omitempty" db:"group,type=VARCHAR(32)"`
+}
@@ -0,0 +1,134 @@
+package sqlm
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// patternNameRegex match the named parameter at the beginning, such as `:name`.
+var patternNameRegex = regexp.MustCompile(`^:\w+(\.\w+)*`)
+
+// ErrorPatternConflict error when the named parameters conflicted in composing sql.
+type ErrorPatternConflict struct {
+	Name string
+}
+
+// Error error message
+func (e *ErrorPatternConflict) Error() string {
+	return fmt.Sprintf("named parameter %q is conflicted with the one of other filter", e.Name)
+}
+
+// mergeWhere merge patterns of the child where into ret, the child pattern names already existed in ret
+// are renamed, and the child format rewritten with the renamed names is returned.
+//
+// It returns *ErrorPatternConflict when the child format references a named parameter which is not
+// provided by the child but by ret, because the value to bind is ambiguous.
+func mergeWhere(ret, child *SQLWhere) (string, error) {
+	if ret.Patterns == nil {
+		ret.Patterns = make(map[string]interface{})
+	}
+
+	for _, name := range patternNames(child.Format) {
+		_, inChild := child.Patterns[name]
+		_, inRet := ret.Patterns[name]
+		if !inChild && inRet {
+			return "", &ErrorPatternConflict{Name: name}
+		}
+	}
+
+	keys := make([]string, 0, len(child.Patterns))
+	taken := make(map[string]bool)
+	for k := range child.Patterns {
+		keys = append(keys, k)
+		taken[k] = true
+	}
+	for k := range ret.Patterns {
+		taken[k] = true
+	}
+	sort.Strings(keys)
+
+	renames := make(map[string]string)
+	for _, k := range keys {
+		if _, exist := ret.Patterns[k]; !exist {
+			continue
+		}
+
+		newKey := k
+		for i := 1; taken[newKey]; i++ {
+			newKey = fmt.Sprintf("%s_%d", k, i)
+		}
+		renames[k] = newKey
+		taken[newKey] = true
+	}
+
+	format := child.Format
+	if len(renames) > 0 {
+		format = rewritePatternNames(format, func(name string) string {
+			if newKey, ok := renames[name]; ok {
+				return newKey
+			}
+			return name
+		})
+	}
+	for k, v := range child.Patterns {
+		if newKey, ok := renames[k]; ok {
+			k = newKey
+		}
+		ret.Patterns[k] = v
+	}
+
+	return format, nil
+}
+
+// patternNames list the named parameters referenced in format.
+func patternNames(format string) []string {
+	var names []string
+	rewritePatternNames(format, func(name string) string {
+		names = append(names, name)
+		return name
+	})
+
+	return names
+}
+
+// rewritePatternNames rewrite the named parameters in format by fn,
+// the quoted literals or identifiers and the `::` escapes are skipped.
+func rewritePatternNames(format string, fn func(name string) string) string {
+	var b strings.Builder
+	var quote byte
+
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && i+1 < len(format) {
+				b.WriteByte(c)
+				i++
+				c = format[i]
+			} else if c == quote {
+				quote = 0
+			}
+			b.WriteByte(c)
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			b.WriteByte(c)
+		case c == ':' && i+1 < len(format) && format[i+1] == ':':
+			b.WriteString("::")
+			i++
+		case c == ':':
+			loc := patternNameRegex.FindStringIndex(format[i:])
+			if loc == nil {
+				b.WriteByte(c)
+				continue
+			}
+			b.WriteString(":" + fn(format[i+1:i+loc[1]]))
+			i += loc[1] - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,116 @@
+package sqlm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_rewritePatternNames(t *testing.T) {
+	upper := func(name string) string { return name + "_x" }
+
+	tests := []struct {
+		name      string
+		format    string
+		want      string
+		wantNames []string
+	}{
+		{"empty", "", "", nil},
+		{"simple", "a=:a AND b BETWEEN :bS AND :bE", "a=:a_x AND b BETWEEN :bS_x AND :bE_x", []string{"a", "bS", "bE"}},
+		{"qualified", "t.a=:t.a", "t.a=:t.a_x", []string{"t.a"}},
+		{"single quoted literal", "a=':a' AND a=:a", "a=':a' AND a=:a_x", []string{"a"}},
+		{"escaped quote in literal", `a='it\'s :a' AND b=:b`, `a='it\'s :a' AND b=:b_x`, []string{"b"}},
+		{"double quoted identifier", `"a:b"=:b`, `"a:b"=:b_x`, []string{"b"}},
+		{"backtick quoted identifier", "`a:b`=:b", "`a:b`=:b_x", []string{"b"}},
+		{"cast escape", "a::text=:a", "a::text=:a_x", []string{"a"}},
+		{"lonely colon", "a = : b", "a = : b", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rewritePatternNames(tt.format, upper); got != tt.want {
+				t.Errorf("rewritePatternNames() = %v, want %v", got, tt.want)
+			}
+			if got := patternNames(tt.format); !reflect.DeepEqual(got, tt.wantNames) {
+				t.Errorf("patternNames() = %v, want %v", got, tt.wantNames)
+			}
+		})
+	}
+}
+
+func Test_mergeWhere(t *testing.T) {
+	tests := []struct {
+		name         string
+		ret          *SQLWhere
+		child        *SQLWhere
+		want         string
+		wantPatterns map[string]interface{}
+		wantErr      bool
+	}{
+		{
+			"no conflict",
+			&SQLWhere{},
+			&SQLWhere{Format: "a=:a", Patterns: map[string]interface{}{"a": 1}},
+			"a=:a",
+			map[string]interface{}{"a": 1},
+			false,
+		},
+		{
+			"renamed",
+			&SQLWhere{Patterns: map[string]interface{}{"a": 1, "a_1": 2}},
+			&SQLWhere{Format: "a=:a OR x=':a'", Patterns: map[string]interface{}{"a": 3}},
+			"a=:a_2 OR x=':a'",
+			map[string]interface{}{"a": 1, "a_1": 2, "a_2": 3},
+			false,
+		},
+		{
+			"renamed not conflict with child names",
+			&SQLWhere{Patterns: map[string]interface{}{"a": 1}},
+			&SQLWhere{Format: "a=:a OR a=:a_1", Patterns: map[string]interface{}{"a": 2, "a_1": 3}},
+			"a=:a_2 OR a=:a_1",
+			map[string]interface{}{"a": 1, "a_1": 3, "a_2": 2},
+			false,
+		},
+		{
+			"update set patterns",
+			&SQLWhere{Patterns: map[string]interface{}{"set_a": 1}},
+			&SQLWhere{Format: "set_a=:set_a", Patterns: map[string]interface{}{"set_a": 2}},
+			"set_a=:set_a_1",
+			map[string]interface{}{"set_a": 1, "set_a_1": 2},
+			false,
+		},
+		{
+			"referenced name provided by other",
+			&SQLWhere{Patterns: map[string]interface{}{"a": 1}},
+			&SQLWhere{Format: "b=:a", Patterns: map[string]interface{}{"b": 2}},
+			"",
+			map[string]interface{}{"a": 1},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeWhere(tt.ret, tt.child)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mergeWhere() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			var conflict *ErrorPatternConflict
+			if tt.wantErr && !errors.As(err, &conflict) {
+				t.Errorf("mergeWhere() error = %v, want *ErrorPatternConflict", err)
+			}
+			if got != tt.want {
+				t.Errorf("mergeWhere() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.ret.Patterns, tt.wantPatterns) {
+				t.Errorf("mergeWhere() patterns = %v, want %v", tt.ret.Patterns, tt.wantPatterns)
+			}
+		})
+	}
+}
+
+func TestErrorPatternConflict_Error(t *testing.T) {
+	err := &ErrorPatternConflict{Name: "a"}
+	if got, want := err.Error(), `named parameter "a" is conflicted with the one of other filter`; got != want {
+		t.Errorf("ErrorPatternConflict.Error() = %v, want %v", got, want)
+	}
+}
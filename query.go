@@ -5,6 +5,31 @@ import (
 	"strings"
 )
 
+// OrderTerm one sort key for ordering.
+type OrderTerm struct {
+	Col        string
+	Desc       bool
+	NullsFirst bool // sort null values first, the database default order of null values is used when false.
+
+	nullsLast bool // sort null values last explicitly, it's set for merging the records of shards.
+}
+
+// String return the term in `ORDER BY` clause.
+func (o OrderTerm) String() string {
+	term := o.Col
+	if o.Desc {
+		term += " DESC"
+	}
+	if o.NullsFirst {
+		// `NULLS FIRST` is not supported by mysql, emulate it in the portable way.
+		term = o.Col + " IS NULL DESC, " + term
+	} else if o.nullsLast {
+		term = o.Col + " IS NULL, " + term
+	}
+
+	return term
+}
+
 // Query sql select query info.
 type Query struct {
 	Distinct      bool
@@ -13,6 +38,13 @@ type Query struct {
 	Where         string
 	OrderByColumn string
 	OrderDesc     bool
+	OrderBy       []OrderTerm // sort keys, it takes precedence over OrderByColumn and OrderDesc.
+	GroupBy       []string
+	Having        string
+	Limit         int64
+	Offset        int64
+
+	dialect Dialect
 }
 
 // String implement interface fmt.Stringer.
@@ -25,11 +57,41 @@ func (s *Query) String() string {
 	if s.Where != "" {
 		query += " where " + s.Where
 	}
-	if s.OrderByColumn != "" {
-		query += " ORDER BY " + s.OrderByColumn
-		if s.OrderDesc {
-			query += " DESC"
-		}
+	if len(s.GroupBy) > 0 {
+		query += " GROUP BY " + strings.Join(s.GroupBy, ", ")
+	}
+	if s.Having != "" {
+		query += " HAVING " + s.Having
+	}
+	if orderBy := s.orderBy(); orderBy != "" {
+		query += " ORDER BY " + orderBy
+	}
+	if limitOffset := s.limitOffset(); limitOffset != "" {
+		query += " " + limitOffset
 	}
 	return query
 }
+
+func (s *Query) orderBy() string {
+	terms := s.OrderBy
+	if len(terms) == 0 && s.OrderByColumn != "" {
+		terms = []OrderTerm{{Col: s.OrderByColumn, Desc: s.OrderDesc}}
+	}
+
+	var ret []string
+	for _, o := range terms {
+		ret = append(ret, o.String())
+	}
+
+	return strings.Join(ret, ", ")
+}
+
+// limitOffset render limit and offset parts in query's dialect, mysql style is used when dialect not setted.
+func (s *Query) limitOffset() string {
+	d := s.dialect
+	if d == nil {
+		d = new(MySQLDialect)
+	}
+
+	return d.LimitOffset(s.Limit, s.Offset)
+}
@@ -0,0 +1,62 @@
+package sqlm
+
+import "testing"
+
+func TestQuery_String(t *testing.T) {
+	tests := []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{
+			"simple",
+			Query{Columns: []string{"a", "b"}, From: "t"},
+			"select  a,b from t",
+		},
+		{
+			"order by column",
+			Query{Columns: []string{"*"}, From: "t", Where: "a=:a", OrderByColumn: "a", OrderDesc: true},
+			"select  * from t where a=:a ORDER BY a DESC",
+		},
+		{
+			"order by terms",
+			Query{
+				Columns:       []string{"*"},
+				From:          "t",
+				OrderByColumn: "x",
+				OrderBy:       []OrderTerm{{Col: "a", Desc: true}, {Col: "b", NullsFirst: true}, {Col: "id"}},
+			},
+			"select  * from t ORDER BY a DESC, b IS NULL DESC, b, id",
+		},
+		{
+			"group by and having",
+			Query{
+				Columns: []string{"a", "COUNT(*) AS cnt"},
+				From:    "t",
+				Where:   "b=:b",
+				GroupBy: []string{"a"},
+				Having:  "COUNT(*) > :cnt",
+				OrderBy: []OrderTerm{{Col: "cnt", Desc: true}},
+				Limit:   5,
+			},
+			"select  a,COUNT(*) AS cnt from t where b=:b GROUP BY a HAVING COUNT(*) > :cnt ORDER BY cnt DESC LIMIT 5",
+		},
+		{
+			"limit and offset without dialect",
+			Query{Distinct: true, Columns: []string{"a"}, From: "t", Limit: 10, Offset: 20},
+			"select distinct a from t LIMIT 10 OFFSET 20",
+		},
+		{
+			"offset of sqlite",
+			Query{Columns: []string{"a"}, From: "t", Offset: 20, dialect: new(SQLiteDialect)},
+			"select  a from t LIMIT -1 OFFSET 20",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.String(); got != tt.want {
+				t.Errorf("Query.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package sqlm
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/ahmetb/go-linq"
@@ -12,9 +14,10 @@ import (
 
 // SQL divers.
 const (
-	DriverMysql   = "mysql"   // DriverMysql mysql driver type
-	DriverSQLite  = "sqlite"  // DriverSQLite sqlite driver type
-	DriverSQLite3 = "sqlite3" // DriverSQLite3 sqlite driver type limit sqlite version: v3+
+	DriverMysql    = "mysql"    // DriverMysql mysql driver type
+	DriverSQLite   = "sqlite"   // DriverSQLite sqlite driver type
+	DriverSQLite3  = "sqlite3"  // DriverSQLite3 sqlite driver type limit sqlite version: v3+
+	DriverPostgres = "postgres" // DriverPostgres postgresql driver type
 )
 
 // record model struct tags.
@@ -37,15 +40,26 @@ const (
 	DBKeyOnUpdate      = "on_update"      // for col schema key: on_update => ON UPDATE.
 	DBKeyComplex       = "complex"        // the column should returned zero when simple list.
 	DBKeySplit         = "split"          // split table by column's value, usually value range is limited.
+	DBKeyIndex         = "index"          // for col schema key: index=name[:seq], columns with same name form composite index.
+	DBKeyUniqueIndex   = "unique_index"   // for col schema key: unique_index=name[:seq].
+	DBKeyForeignKey    = "fk"             // for col schema key: fk=table(col) => FOREIGN KEY.
+	DBKeyOnDelete      = "on_delete"      // for col schema key: on_delete => ON DELETE of foreign key.
+	DBKeySoftDelete    = "soft_delete"    // the nullable column stores deleted time, Table#Delete() sets it instead of deleting.
+	DBKeyVersion       = "version"        // the integer column for optimistic locking in Table#Save().
 )
 
+// indexNameSep separate the multiple indexes of one column, like: `index=idx_a|idx_b:2`.
+const indexNameSep = "|"
+
 // SQL keywords.
 const (
-	AttrOnUpdateMySQL = "ON UPDATE" // AttrOnUpdateMySQL `on update` attribute for mysql table DDL.
-	AttrNotNullMySQL  = "NOT NULL"  // AttrNotNullMySQL `not null` attribute for mysql table DDL.
-	AttrNotNullSQLite = "NOT NULL"  // AttrNotNullSQLite `not null` attribute for sqlite table DDL.
-	AttrDefaultMySQL  = "DEFAULT"   // AttrDefaultMySQL `default` attribute for mysql table DDL.
-	AttrDefaultSQLite = "DEFAULT"   // AttrDefaultSQLite `default` attribute for sqlite table DDL.
+	AttrOnUpdateMySQL   = "ON UPDATE" // AttrOnUpdateMySQL `on update` attribute for mysql table DDL.
+	AttrNotNullMySQL    = "NOT NULL"  // AttrNotNullMySQL `not null` attribute for mysql table DDL.
+	AttrNotNullSQLite   = "NOT NULL"  // AttrNotNullSQLite `not null` attribute for sqlite table DDL.
+	AttrDefaultMySQL    = "DEFAULT"   // AttrDefaultMySQL `default` attribute for mysql table DDL.
+	AttrDefaultSQLite   = "DEFAULT"   // AttrDefaultSQLite `default` attribute for sqlite table DDL.
+	AttrNotNullPostgres = "NOT NULL"  // AttrNotNullPostgres `not null` attribute for postgresql table DDL.
+	AttrDefaultPostgres = "DEFAULT"   // AttrDefaultPostgres `default` attribute for postgresql table DDL.
 
 	DateTimeType         = "DATETIME"          // for db datetime type.
 	DBFnCurrentTimestamp = "CURRENT_TIMESTAMP" // is `CURRENT_TIMESTAMP`.
@@ -58,15 +72,36 @@ const (
 )
 
 const (
-	singlePKCount        = 1             // primary keys count for single primary key.
-	attrKey              = "KEY"         // common key.
-	attrPrimaryKey       = "PRIMARY KEY" // attr for primary key.
-	attrUniqueKeyMySQL   = "UNIQUE KEY"  // attr for mysql unique key.
-	attrUniqueKeySQLite  = "UNIQUE"      // attr for sqlite unique key.
-	emptyStrColAsignPart = "''"          // zero string type column set value.
-	tableCreateSQLTpl    = "CREATE TABLE IF NOT EXISTS %s (\n%s\n)"
+	singlePKCount           = 1             // primary keys count for single primary key.
+	attrKey                 = "KEY"         // common key.
+	attrPrimaryKey          = "PRIMARY KEY" // attr for primary key.
+	attrUniqueKeyMySQL      = "UNIQUE KEY"  // attr for mysql unique key.
+	attrUniqueKeySQLite     = "UNIQUE"      // attr for sqlite unique key.
+	attrUniqueKeyPostgres   = "UNIQUE"      // attr for postgresql unique key.
+	emptyStrColAsignPart    = "''"          // zero string type column set value.
+	tableCreateSQLTpl       = "CREATE TABLE IF NOT EXISTS %s (\n%s\n)"
+	indexCreateSQLTpl       = "CREATE INDEX IF NOT EXISTS %s ON %s (%s)"
+	uniqueIndexCreateSQLTpl = "CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s (%s)"
+	foreignKeySQLTpl        = "FOREIGN KEY (%s) REFERENCES %s (%s)"
+	insertSQLTpl            = "INSERT INTO %s (%s) VALUES (%s)"
+	sqlStatementSep         = ";\n"
 )
 
+// splitValueRegex limit the split column value which used as suffix of target table name.
+var splitValueRegex = regexp.MustCompile(`^[0-9A-Za-z_-]+$`)
+
+// foreignKeyRegex parse the referenced table and column of foreign key, like: `rules(id)`.
+var foreignKeyRegex = regexp.MustCompile(`^(\w+)\s*\(\s*(\w+)\s*\)$`)
+
+// onDeleteActions actions of `on_delete` option for foreign key.
+var onDeleteActions = map[string]string{
+	"cascade":     "CASCADE",
+	"set_null":    "SET NULL",
+	"set_default": "SET DEFAULT",
+	"restrict":    "RESTRICT",
+	"no_action":   "NO ACTION",
+}
+
 // ColSchema for table column.
 type ColSchema struct {
 	Name          string
@@ -85,49 +120,20 @@ type ColSchema struct {
 	AutoIncrement bool
 	Complex       bool
 	Split         bool
-}
-
-func (c *ColSchema) colSchemaSQLite(onlyOnePrimaryCol bool) string {
-	line := fmt.Sprintf("%s %s", c.Name, c.Type)
-	if c.NotNull {
-		line += fmt.Sprintf(" %s", AttrNotNullSQLite)
-	}
-	if c.Default && c.DefaultStr != "" {
-		line += fmt.Sprintf(" %s %s", AttrDefaultSQLite, c.DefaultStr)
-	}
-	if onlyOnePrimaryCol && c.Primary {
-		line += fmt.Sprintf(" %s", attrPrimaryKey)
-	}
-	if c.Unique {
-		line += fmt.Sprintf(" %s", attrUniqueKeySQLite)
-	}
+	SoftDelete    bool
+	Version       bool
+	Indexes       []ColIndex // indexes declared by `index` and `unique_index`.
+	ForeignKey    string     // referenced table and column declared by `fk`, like: `rules(id)`.
+	OnDelete      string     // action declared by `on_delete` when the referenced row is deleted.
 
-	return line
+	goType reflect.Type // type of the struct field, for inferring column type when Type is empty.
 }
 
-func (c *ColSchema) colSchemaMysql(autoIncrementExp string, onlyOnePrimaryCol bool) string {
-	line := fmt.Sprintf("%s %s", c.Name, c.Type)
-
-	if c.NotNull {
-		line += fmt.Sprintf(" %s", AttrNotNullMySQL)
-	}
-	if c.Default && c.DefaultStr != "" {
-		line += fmt.Sprintf(" %s %s", AttrDefaultMySQL, c.DefaultStr)
-	}
-	if c.AutoUpdate && c.AutoUpdateStr != "" {
-		line += fmt.Sprintf(" %s %s", AttrOnUpdateMySQL, c.AutoUpdateStr)
-	}
-	if onlyOnePrimaryCol && c.Primary {
-		line += fmt.Sprintf(" %s", attrPrimaryKey)
-	}
-	if c.Unique {
-		line += fmt.Sprintf(" %s", attrUniqueKeyMySQL)
-	}
-	if c.AutoIncrement {
-		line += autoIncrementExp
-	}
-
-	return line
+// ColIndex index which the column belongs to, Seq is the position of column in the composite index.
+type ColIndex struct {
+	Name   string
+	Seq    int
+	Unique bool
 }
 
 // set key attr, order is : primary key > unique key > key
@@ -207,14 +213,16 @@ func (t *TableSchema) TargetNameWithFilter(filter RowFilter) (string, error) {
 		return "", nil
 	}
 
+	// 只有等值条件可以确定分表, 比较/模糊/取反等条件需要查询所有分表
+	patterns, err := equalPatterns(filter)
+	if err != nil {
+		return "", err
+	}
+
 	for _, c := range t.splitByColumns {
-		errColMiss := fmt.Errorf("col %s is required in where patterns for compute target table name", c)
-		if len(where.Patterns) == 0 {
-			return "", errColMiss
-		}
-		v, ok := where.Patterns[c]
+		v, ok := patterns[c]
 		if !ok {
-			return "", errColMiss
+			return "", &ErrorSplitColMissing{Col: c}
 		}
 
 		switch v.(type) {
@@ -223,7 +231,11 @@ func (t *TableSchema) TargetNameWithFilter(filter RowFilter) (string, error) {
 		case []byte:
 			return "", fmt.Errorf("col %s for compute target table name should not to be []byte", c)
 		default:
-			ret = fmt.Sprintf("%s_%v", ret, v)
+			suffix := fmt.Sprintf("%v", v)
+			if !splitValueRegex.MatchString(suffix) {
+				return "", fmt.Errorf("col %s value %q for compute target table name contains illegal characters", c, suffix)
+			}
+			ret = ret + "_" + suffix
 		}
 	}
 
@@ -241,6 +253,22 @@ func (t *TableSchema) ColNames(all bool) []string {
 	return cols
 }
 
+// hasCol report whether the column exists in table.
+func (t *TableSchema) hasCol(name string) bool {
+	return t.col(name) != nil
+}
+
+// col return the column by name, nil when not exists.
+func (t *TableSchema) col(name string) *ColSchema {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
 // ComplexColNames list complex columns for list
 func (t *TableSchema) ComplexColNames() []string {
 	var cols []string
@@ -252,10 +280,16 @@ func (t *TableSchema) ComplexColNames() []string {
 	return cols
 }
 
-// KeyCol return key col name for table
+// KeyCol return key col name for table, the auto increment column is not key col when it's settled as
+// primary key for none explicit primary keys.
 func (t *TableSchema) KeyCol() string {
+	var hasPrimary bool
+	for _, c := range t.Columns {
+		hasPrimary = hasPrimary || c.Primary
+	}
+
 	for _, c := range t.Columns {
-		shouldBeCommonKey := (c.AutoIncrement || c.Key) && (!c.Primary)
+		shouldBeCommonKey := (c.Key || (c.AutoIncrement && hasPrimary)) && (!c.Primary)
 		if shouldBeCommonKey {
 			return c.Name
 		}
@@ -266,40 +300,44 @@ func (t *TableSchema) KeyCol() string {
 
 // PrimaryCols return primary cols for table
 func (t *TableSchema) PrimaryCols() ([]string, error) {
-	var ret []string
+	_, ret, err := t.settledColumns()
+	return ret, err
+}
 
-	var autoIncrementCol *ColSchema
+// settledColumns return copies of columns with the auto increment column settled by dialect,
+// and the final primary columns. The columns of schema are not changed.
+func (t *TableSchema) settledColumns() ([]*ColSchema, []string, error) {
+	var primaryCols []string
+	autoIncrementIndex := -1
+	columns := make([]*ColSchema, 0, len(t.Columns))
 	for i, c := range t.Columns {
+		col := *c
+		columns = append(columns, &col)
 		if c.Primary {
-			ret = append(ret, c.Name)
+			primaryCols = append(primaryCols, c.Name)
 		}
 		if c.AutoIncrement {
-			autoIncrementCol = t.Columns[i]
+			autoIncrementIndex = i
 		}
 	}
 
 	// none explicit primary keys, find auto increase key to set it as primary key.
-	if autoIncrementCol == nil {
-		return ret, nil
+	if autoIncrementIndex < 0 {
+		return columns, primaryCols, nil
 	}
 
-	switch t.Driver {
-	case DriverSQLite, DriverSQLite3:
-		if len(ret) > 0 {
-			return nil, errors.New("sqlite not support both auto increment and other primary columns at same time")
-		}
-		sqliteAutoIncrementColDeal(autoIncrementCol)
-	case DriverMysql:
-		if len(ret) > 0 {
-			return ret, nil
-		}
+	dialect, err := GetDialect(t.Driver)
+	if err != nil {
+		return columns, nil, nil
+	}
 
-		mysqlAutoIncrementColDeal(autoIncrementCol)
-	default:
-		return nil, nil
+	col, primaryCols, err := dialect.AutoIncrement(*columns[autoIncrementIndex], primaryCols)
+	if err != nil {
+		return nil, nil, err
 	}
+	columns[autoIncrementIndex] = &col
 
-	return append(ret, autoIncrementCol.Name), nil
+	return columns, primaryCols, nil
 }
 
 // InsertCols list all columns that should fill when inserting
@@ -325,11 +363,12 @@ func (t *TableSchema) UpdateCols() []string {
 	return ret
 }
 
-// UpdateColsWhenDup for insert when exist dup with same primary keys
+// UpdateColsWhenDup for insert when exist dup with same primary keys,
+// the version column is excluded which is increased by Table#Save().
 func (t *TableSchema) UpdateColsWhenDup() []string {
 	var ret []string
 	for _, c := range t.Columns {
-		shouldUpdate := !c.Primary && !c.AutoIncrement && !c.NotUpdate && !c.AutoUpdate && !c.Split
+		shouldUpdate := !c.Primary && !c.AutoIncrement && !c.NotUpdate && !c.AutoUpdate && !c.Split && !c.Version && !c.SoftDelete
 		if shouldUpdate {
 			ret = append(ret, c.Name)
 		}
@@ -341,77 +380,357 @@ func (t *TableSchema) UpdateColsWhenDup() []string {
 func (t *TableSchema) UpdatePatternsWhenDup() string {
 	var updatePatterns []string
 	for _, k := range t.UpdateColsWhenDup() {
-		updatePatterns = append(updatePatterns, fmt.Sprintf("%s=:%s", k, k))
+		updatePatterns = append(updatePatterns, fmt.Sprintf("%s=:%s", t.quote(k), k))
 	}
 	return strings.Join(updatePatterns, ",")
 }
 
-// schema return table's all columns schema
-func (t *TableSchema) schema() (string, error) {
-	switch t.Driver {
-	case DriverMysql:
-		return t.schemaMysql()
-	case DriverSQLite, DriverSQLite3:
-		return t.schemaSQLite()
-	default:
-		return "", fmt.Errorf("not support driver: %s", t.Driver)
+// AutoIncrementCol return the auto increment column name, empty if not exist.
+func (t *TableSchema) AutoIncrementCol() string {
+	for _, c := range t.Columns {
+		if c.AutoIncrement {
+			return c.Name
+		}
 	}
+
+	return ""
 }
 
-func (t *TableSchema) schemaMysql() (string, error) {
-	var onlyOnePrimaryCol bool
+// VersionCol return the column declared by `version`, empty when the table is not optimistic locked.
+func (t *TableSchema) VersionCol() string {
+	for _, c := range t.Columns {
+		if c.Version {
+			return c.Name
+		}
+	}
+
+	return ""
+}
 
-	primaryKeys, err := t.PrimaryCols()
+// SoftDeleteCol return the column declared by `soft_delete`, empty when the table is not soft deleted.
+func (t *TableSchema) SoftDeleteCol() string {
+	for _, c := range t.Columns {
+		if c.SoftDelete {
+			return c.Name
+		}
+	}
+
+	return ""
+}
+
+// deletedFilter restrict the filter with the scope of soft deleted records.
+func (t *TableSchema) deletedFilter(rf RowFilter, scope DeletedScope) RowFilter {
+	col := t.SoftDeleteCol()
+	if col == "" || scope == DeletedIncluded {
+		return rf
+	}
+
+	deleted := NullFilter{Col: col, NotNull: scope == DeletedOnly}
+	if rf == nil {
+		return deleted
+	}
+
+	// 软删除条件在前, rf 作为子条件加括号
+	return RowFilterAnd{deleted, rf}
+}
+
+// InsertSQL return sql statement for inserting record into target table,
+// the statement returns the auto increment column value when the driver not supports LastInsertId.
+func (t *TableSchema) InsertSQL(targetTable string) string {
+	dialect, err := GetDialect(t.Driver)
+	if err != nil {
+		return ""
+	}
+
+	query := t.insertSQL(targetTable)
+	if col := t.AutoIncrementCol(); col != "" {
+		if returning := dialect.InsertReturning(col); returning != "" {
+			query += " " + returning
+		}
+	}
+
+	return query
+}
+
+// UpsertSQL return sql statement for inserting record into target table,
+// the exist record with same primary or unique keys will be updated.
+func (t *TableSchema) UpsertSQL(targetTable string) (string, error) {
+	dialect, err := GetDialect(t.Driver)
+	if err != nil {
+		return "", err
+	}
+
+	conflictCols, err := t.conflictCols()
 	if err != nil {
 		return "", err
 	}
 
-	if len(primaryKeys) == singlePKCount {
-		onlyOnePrimaryCol = true
+	var updateCols []string
+	linq.From(t.UpdateColsWhenDup()).
+		Except(linq.From(conflictCols)).
+		ToSlice(&updateCols)
+
+	var increaseCols []string
+	if col := t.VersionCol(); col != "" {
+		increaseCols = append(increaseCols, col)
+	}
+
+	query, err := dialect.Upsert(targetTable, t.insertSQL(targetTable), conflictCols, updateCols, increaseCols)
+	if err != nil {
+		return "", err
+	}
+
+	if col := t.AutoIncrementCol(); col != "" {
+		if returning := dialect.InsertReturning(col); returning != "" {
+			query += " " + returning
+		}
+	}
+
+	return query, nil
+}
+
+// BatchInsertSQL return sql statement for inserting multiple rows into target table in one statement,
+// the named parameters of row i are suffixed by `_i`, such as: `:name_0`, `:name_1`.
+func (t *TableSchema) BatchInsertSQL(targetTable string, rows int) string {
+	dialect, err := GetDialect(t.Driver)
+	if err != nil || rows <= 0 {
+		return ""
+	}
+
+	insertKeys := t.InsertCols()
+
+	values := make([]string, 0, rows)
+	for i := 0; i < rows; i++ {
+		var insertPatterns []string
+		for _, k := range insertKeys {
+			insertPatterns = append(insertPatterns, ":"+batchPatternName(k, i))
+		}
+		values = append(values, strings.Join(insertPatterns, ","))
+	}
+
+	cols := strings.Join(t.quotes(insertKeys), ",")
+	query := fmt.Sprintf(insertSQLTpl, t.quoteTable(targetTable), cols, strings.Join(values, "),("))
+	if col := t.AutoIncrementCol(); col != "" {
+		if returning := dialect.InsertReturning(col); returning != "" {
+			query += " " + returning
+		}
+	}
+
+	return query
+}
+
+func (t *TableSchema) insertSQL(targetTable string) string {
+	var insertPatterns []string
+	insertKeys := t.InsertCols()
+	for _, k := range insertKeys {
+		insertPatterns = append(insertPatterns, ":"+k)
+	}
+
+	cols := strings.Join(t.quotes(insertKeys), ",")
+	return fmt.Sprintf(insertSQLTpl, t.quoteTable(targetTable), cols, strings.Join(insertPatterns, ","))
+}
+
+// batchPatternName return the named parameter of column for the row in batch inserting.
+func batchPatternName(col string, row int) string {
+	return fmt.Sprintf("%s_%d", col, row)
+}
+
+// conflictCols return columns for detecting conflict when upserting: the primary columns when they are
+// supplied by inserting, otherwise the first unique column or unique index, such as the auto increment
+// primary column is not inserted.
+func (t *TableSchema) conflictCols() ([]string, error) {
+	pCols, err := t.PrimaryCols()
+	if err != nil {
+		return nil, err
+	}
+	insertCols := t.InsertCols()
+	if len(pCols) > 0 && containsAll(insertCols, pCols) {
+		return pCols, nil
 	}
-	autoIncrementExp := " " + autoIncrementKey(t.Driver)
 
-	var lines []string
 	for _, c := range t.Columns {
-		if c.AutoIncrement {
-			c.NotNull = true
+		if c.Unique && containsString(insertCols, c.Name) {
+			return []string{c.Name}, nil
 		}
-		lines = append(lines, c.colSchemaMysql(autoIncrementExp, onlyOnePrimaryCol))
 	}
 
-	// primary key 和 索引的设置
-	if len(primaryKeys) > singlePKCount {
-		lines = append(lines, fmt.Sprintf("%s (%s)", attrPrimaryKey, strings.Join(primaryKeys, ",")))
+	indexes, err := t.Indexes()
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range indexes {
+		if index.Unique && containsAll(insertCols, index.Cols) {
+			return index.Cols, nil
+		}
 	}
 
-	if key := t.KeyCol(); key != "" {
-		lines = append(lines, fmt.Sprintf("%s %s (%s)", attrKey, key, key))
+	return pCols, nil
+}
+
+// containsAll report whether all items are in list.
+func containsAll(list, items []string) bool {
+	for _, item := range items {
+		if !containsString(list, item) {
+			return false
+		}
 	}
 
-	return strings.Join(lines, ",\n"), nil
+	return true
 }
 
-func (t *TableSchema) schemaSQLite() (string, error) {
-	var onlyOnePrimaryCol bool
-	primaryKeys, err := t.PrimaryCols()
+// schema return table's all columns schema and the standalone index creating statements.
+func (t *TableSchema) schema() (string, []string, error) {
+	dialect, err := GetDialect(t.Driver)
 	if err != nil {
-		return "", nil
+		return "", nil, err
 	}
 
-	if len(primaryKeys) == singlePKCount {
-		onlyOnePrimaryCol = true
+	columns, primaryKeys, err := t.settledColumns()
+	if err != nil {
+		return "", nil, err
 	}
+	onlyOnePrimaryCol := len(primaryKeys) == singlePKCount
 
 	var lines []string
-	for _, c := range t.Columns {
-		lines = append(lines, c.colSchemaSQLite(onlyOnePrimaryCol))
+	for _, c := range columns {
+		lines = append(lines, dialect.ColumnDDL(c, onlyOnePrimaryCol))
 	}
 
+	// primary key 和 索引的设置
 	if len(primaryKeys) > singlePKCount {
-		lines = append(lines, fmt.Sprintf("%s (%s)", attrPrimaryKey, strings.Join(primaryKeys, ",")))
+		lines = append(lines, fmt.Sprintf("%s (%s)", attrPrimaryKey, strings.Join(t.quotes(primaryKeys), ",")))
+	}
+
+	foreignKeys, err := t.ForeignKeys()
+	if err != nil {
+		return "", nil, err
+	}
+	for _, fk := range foreignKeys {
+		line := fmt.Sprintf(foreignKeySQLTpl, t.quote(fk.Col), t.quote(fk.RefTable), t.quote(fk.RefCol))
+		if fk.OnDelete != "" {
+			line += " ON DELETE " + fk.OnDelete
+		}
+		lines = append(lines, line)
 	}
 
-	return strings.Join(lines, ",\n"), nil
+	indexes, err := t.Indexes()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var indexSQLs []string
+	for _, index := range indexes {
+		ddl, inline := dialect.IndexDDL(t.Name, index.Name, index.Cols, index.Unique)
+		if inline {
+			lines = append(lines, ddl)
+		} else {
+			indexSQLs = append(indexSQLs, ddl)
+		}
+	}
+
+	return strings.Join(lines, ",\n"), indexSQLs, nil
+}
+
+// IndexSchema index of table.
+type IndexSchema struct {
+	Name   string
+	Cols   []string
+	Unique bool
+}
+
+// Indexes return the indexes declared apart from columns, the unique columns are not included.
+// The columns of composite index are ordered by their seq, then the order of fields.
+func (t *TableSchema) Indexes() ([]IndexSchema, error) {
+	var ret []IndexSchema
+	if key := t.KeyCol(); key != "" {
+		ret = append(ret, IndexSchema{Name: key, Cols: []string{key}})
+	}
+
+	type indexCol struct {
+		name string
+		seq  int
+	}
+
+	var declared []IndexSchema
+	colsOfIndex := make(map[string][]indexCol)
+	uniqueOfIndex := make(map[string]bool)
+	for _, c := range t.Columns {
+		for _, index := range c.Indexes {
+			unique, ok := uniqueOfIndex[index.Name]
+			if !ok {
+				declared = append(declared, IndexSchema{Name: index.Name, Unique: index.Unique})
+				uniqueOfIndex[index.Name] = index.Unique
+			} else if unique != index.Unique {
+				return nil, &ErrorSQLInvalid{Message: fmt.Sprintf("index %s is declared as both unique and not unique", index.Name)}
+			}
+			colsOfIndex[index.Name] = append(colsOfIndex[index.Name], indexCol{name: c.Name, seq: index.Seq})
+		}
+	}
+
+	for _, index := range declared {
+		cols := colsOfIndex[index.Name]
+		sort.SliceStable(cols, func(i, j int) bool { return cols[i].seq < cols[j].seq })
+		for _, c := range cols {
+			index.Cols = append(index.Cols, c.name)
+		}
+		ret = append(ret, index)
+	}
+
+	return ret, nil
+}
+
+// ForeignKeySchema foreign key of table, OnDelete is the sql action like `CASCADE`.
+type ForeignKeySchema struct {
+	Col      string
+	RefTable string
+	RefCol   string
+	OnDelete string
+}
+
+// ForeignKeys return the foreign keys declared by `fk` in the order of fields.
+func (t *TableSchema) ForeignKeys() ([]ForeignKeySchema, error) {
+	var ret []ForeignKeySchema
+	for _, c := range t.Columns {
+		if c.ForeignKey == "" {
+			if c.OnDelete != "" {
+				return nil, &ErrorSQLInvalid{Message: fmt.Sprintf("column %s declares on_delete without fk", c.Name)}
+			}
+			continue
+		}
+
+		matches := foreignKeyRegex.FindStringSubmatch(strings.TrimSpace(c.ForeignKey))
+		if matches == nil {
+			return nil, &ErrorSQLInvalid{Message: fmt.Sprintf("invalid foreign key %q of column %s, want table(col)", c.ForeignKey, c.Name)}
+		}
+
+		fk := ForeignKeySchema{Col: c.Name, RefTable: matches[1], RefCol: matches[2]}
+		if c.OnDelete != "" {
+			action, ok := onDeleteActions[strings.ToLower(c.OnDelete)]
+			if !ok {
+				return nil, &ErrorSQLInvalid{Message: fmt.Sprintf("invalid on_delete %q of column %s", c.OnDelete, c.Name)}
+			}
+			fk.OnDelete = action
+		}
+		ret = append(ret, fk)
+	}
+
+	return ret, nil
+}
+
+// RefTables return the tables referenced by foreign keys except itself, which should be created before the table.
+func (t *TableSchema) RefTables() []string {
+	foreignKeys, _ := t.ForeignKeys()
+
+	var ret []string
+	seen := map[string]bool{t.Name: true}
+	for _, fk := range foreignKeys {
+		if !seen[fk.RefTable] {
+			seen[fk.RefTable] = true
+			ret = append(ret, fk.RefTable)
+		}
+	}
+
+	return ret
 }
 
 // CreateSQL return sql statement for creating table, like:
@@ -421,53 +740,76 @@ func (t *TableSchema) schemaSQLite() (string, error) {
 // 	   last_name text NOT NULL
 //   );
 func (t *TableSchema) CreateSQL() string {
-	schemaSQL, err := t.schema()
+	statements, err := t.createSQLs()
 	if err != nil {
 		return ""
 	}
 
-	query := fmt.Sprintf(tableCreateSQLTpl, t.Name, schemaSQL)
-	return query
+	return strings.Join(statements, sqlStatementSep)
+}
+
+// createSQLs return the table creating statement and the standalone index creating statements.
+func (t *TableSchema) createSQLs() ([]string, error) {
+	schemaSQL, indexSQLs, err := t.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(tableCreateSQLTpl, t.quoteTable(t.Name), schemaSQL)
+	return append([]string{query}, indexSQLs...), nil
 }
 
 // SelectSQL return sql statement for select quering
 func (t *TableSchema) SelectSQL(rf RowFilter, options ListOptions) (Query, map[string]interface{}, error) {
+	// 根据 filter 分表
+	targetTable, err := t.TargetName(rf)
+	if err != nil {
+		return Query{}, nil, err
+	}
+
+	return t.selectSQL(targetTable, rf, options)
+}
+
+// selectSQL return sql statement for select quering from the target table.
+func (t *TableSchema) selectSQL(targetTable string, rf RowFilter, options ListOptions) (Query, map[string]interface{}, error) {
 	var selectStatement Query
 
 	if !options.AllColumns && len(options.Columns) > 0 {
-		selectStatement.Columns = options.Columns
+		selectStatement.Columns = t.quotes(options.Columns)
 	} else {
 		if options.AllColumns {
 			selectStatement.Columns = []string{"*"}
 		} else {
-			selectStatement.Columns = t.ColNames(options.AllColumns)
+			selectStatement.Columns = t.quotes(t.ColNames(options.AllColumns))
 		}
 	}
 
-	// 根据 filter 分表
-	targetTable, err := t.TargetName(rf)
+	selectStatement.From = t.quoteTable(targetTable)
+	selectStatement.Limit = int64(options.Limit)
+	selectStatement.Offset = int64(options.Offset)
+	selectStatement.dialect, _ = GetDialect(t.Driver)
+	selectStatement.Distinct = options.Distinct
+	orderBy, err := t.orderBy(options.orderTerms())
 	if err != nil {
 		return selectStatement, nil, err
 	}
-	selectStatement.From = targetTable
-	selectStatement.Distinct = options.Distinct
-	selectStatement.OrderByColumn = options.OrderByColumn
-	selectStatement.OrderDesc = options.OrderDesc
+	selectStatement.OrderBy = orderBy
 	if options.Distinct {
 		// 使用distinct了,不能查询 key键
 		var newColumns []string
-		keyCol := t.KeyCol()
+		keyCol := t.quote(t.KeyCol())
 		linq.From(selectStatement.Columns).
 			Where(func(e interface{}) bool { return e.(string) != keyCol }).
 			ToSlice(&newColumns)
 		selectStatement.Columns = newColumns
 	}
 
+	rf = t.deletedFilter(rf, options.Deleted)
 	if rf == nil {
 		return selectStatement, nil, err
 	}
 
-	where, err := rf.WherePattern()
+	where, err := t.wherePattern(rf)
 	if err != nil {
 		return selectStatement, nil, fmt.Errorf("where statement composed failed: %w", err)
 	}
@@ -479,26 +821,72 @@ func (t *TableSchema) SelectSQL(rf RowFilter, options ListOptions) (Query, map[s
 	if where.Join != nil {
 		midTableName := "t"
 		selectStatement.From = selectStatement.From + " " + midTableName
-		selectStatement.Where = strings.Replace(selectStatement.Where, where.Join.OriginTablePlaceholder, t.Name, -1)
+		selectStatement.Where = strings.Replace(selectStatement.Where, where.Join.OriginTablePlaceholder, t.quoteTable(t.Name), -1)
 		selectStatement.Where = strings.Replace(selectStatement.Where, where.Join.TempTablePlaceholder, midTableName, -1)
 	}
 
 	return selectStatement, where.Patterns, err
 }
 
-// UniqWhereFormatter uniq record select filter
-func UniqWhereFormatter(t *Table) string {
-	var whereFormater []string
+// orderBy validate the sort keys with table columns and quote them.
+func (t *TableSchema) orderBy(terms []OrderTerm) ([]OrderTerm, error) {
+	var ret []OrderTerm
+	for _, o := range terms {
+		if !t.hasCol(o.Col) {
+			return nil, &ErrorSQLInvalid{Message: fmt.Sprintf("unknown order column: %s", o.Col)}
+		}
+
+		o.Col = t.quote(o.Col)
+		ret = append(ret, o)
+	}
+
+	return ret, nil
+}
 
-	pCols, err := t.getSchema().PrimaryCols()
+// wherePattern compose where statement of the filter for schema's dialect.
+func (t *TableSchema) wherePattern(rf RowFilter) (*SQLWhere, error) {
+	dialect, err := GetDialect(t.Driver)
 	if err != nil {
-		return ""
+		return rf.WherePattern()
 	}
 
-	for _, k := range pCols {
-		whereFormater = append(whereFormater, fmt.Sprintf("%s=:%s", k, k))
+	return WherePatternWithDialect(rf, dialect)
+}
+
+// quote identifier such as table name or column name for schema's dialect,
+// it's returned directly when no dialect registered for the driver.
+func (t *TableSchema) quote(identifier string) string {
+	dialect, err := GetDialect(t.Driver)
+	if err != nil {
+		return identifier
 	}
-	return strings.Join(whereFormater, " AND ")
+
+	return quoteIdentifier(dialect, identifier)
+}
+
+// quoteTable quote table name for schema's dialect.
+func (t *TableSchema) quoteTable(table string) string {
+	dialect, err := GetDialect(t.Driver)
+	if err != nil {
+		return table
+	}
+
+	return quoteTableName(dialect, table)
+}
+
+// quotes quote identifiers for schema's dialect.
+func (t *TableSchema) quotes(identifiers []string) []string {
+	dialect, err := GetDialect(t.Driver)
+	if err != nil {
+		return identifiers
+	}
+
+	return quoteIdentifiers(dialect, identifiers)
+}
+
+// UniqWhereFormatter uniq record select filter
+func UniqWhereFormatter(t *Table) string {
+	return t.uniqWhereFormatter()
 }
 
 func colSchemaDefault(f *reflectx.FieldInfo) (defaultOn bool, defaultVal string) {
@@ -563,6 +951,27 @@ func stringColSchemaValSetter(field *reflectx.FieldInfo, value string) string {
 	return setVal
 }
 
+// colSchemaIndexes parse the indexes declared in tag option, like: `idx_a|idx_b:2`.
+func colSchemaIndexes(option string, unique bool) []ColIndex {
+	var ret []ColIndex
+	for _, part := range strings.Split(option, indexNameSep) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		index := ColIndex{Name: part, Unique: unique}
+		if i := strings.LastIndex(part, ":"); i > 0 {
+			if seq, err := strconv.Atoi(part[i+1:]); err == nil {
+				index.Name, index.Seq = part[:i], seq
+			}
+		}
+		ret = append(ret, index)
+	}
+
+	return ret
+}
+
 func colSchemas(t reflect.Type) []*ColSchema {
 	structFieldJSONMap := parseStructJSONMap(t)
 
@@ -570,6 +979,11 @@ func colSchemas(t reflect.Type) []*ColSchema {
 	var schemas []*ColSchema
 	fields := reflectx.NewMapper(DBSchemaTag).TypeMap(t).Tree.Children
 	for _, f := range fields {
+		// 预加载关联记录的字段不是列
+		if f != nil && f.Field.Tag.Get(DBPreloadTag) != "" {
+			continue
+		}
+
 		column := colSchema(f, structFieldJSONMap)
 		if column == nil {
 			continue
@@ -599,8 +1013,8 @@ func colSchema(field *reflectx.FieldInfo, structFieldJSONMap map[string]string)
 		return nil
 	}
 
-	// TODO: db类型在没有显示说明时，能从go类型中自动猜测
-	column := ColSchema{Name: field.Name}
+	// db类型在没有显示说明时，由方言根据go类型推断
+	column := ColSchema{Name: field.Name, goType: field.Field.Type}
 	if jv, ok := structFieldJSONMap[field.Field.Name]; ok {
 		column.JSONName = jv
 	}
@@ -627,6 +1041,8 @@ func colSchema(field *reflectx.FieldInfo, structFieldJSONMap map[string]string)
 		DBKeyNotNull:       &column.NotNull,
 		DBKeyNotInsert:     &column.NotInsert,
 		DBKeyNotUpdate:     &column.NotUpdate,
+		DBKeySoftDelete:    &column.SoftDelete,
+		DBKeyVersion:       &column.Version,
 	}
 
 	for s, p := range switchMap {
@@ -636,32 +1052,20 @@ func colSchema(field *reflectx.FieldInfo, structFieldJSONMap map[string]string)
 		}
 	}
 
-	column.setKeyAttrs()
+	// 同名索引的列组成联合索引
+	column.Indexes = append(colSchemaIndexes(field.Options[DBKeyIndex], false),
+		colSchemaIndexes(field.Options[DBKeyUniqueIndex], true)...)
 
-	return &column
-}
+	// 外键, 在 TableSchema.ForeignKeys() 中校验
+	column.ForeignKey = field.Options[DBKeyForeignKey]
+	column.OnDelete = field.Options[DBKeyOnDelete]
 
-func autoIncrementKey(driver string) string {
-	m := map[string]string{
-		DriverMysql:   "AUTO_INCREMENT",
-		DriverSQLite:  "AUTOINCREMENT",
-		DriverSQLite3: "AUTOINCREMENT",
-		// Add more...
+	// 软删除列插入时为 NULL
+	if column.SoftDelete {
+		column.NotInsert = true
 	}
 
-	v := m[driver]
-	return v
-}
-
-func sqliteAutoIncrementColDeal(column *ColSchema) {
-	column.AutoIncrement = false
-	column.Primary = true
-	column.Type = "INTEGER"
 	column.setKeyAttrs()
-	column.NotNull = false
-}
 
-func mysqlAutoIncrementColDeal(column *ColSchema) {
-	column.Primary = true
-	column.setKeyAttrs()
+	return &column
 }
@@ -1,12 +1,14 @@
 package sqlm
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/ahmetb/go-linq"
+	"github.com/jmoiron/sqlx"
 )
 
 // nolint: unparam
@@ -52,6 +54,15 @@ func TestTableSchemaColNames(t *testing.T) {
 	}
 }
 
+// testProjectFilter custom filter not implementing EqualRowFilter.
+type testProjectFilter struct {
+	projectID int
+}
+
+func (f testProjectFilter) WherePattern() (*SQLWhere, error) {
+	return &SQLWhere{Format: "projectId=:projectId", Patterns: map[string]interface{}{"projectId": f.projectID}}, nil
+}
+
 func TestTableSchemaTargetName(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -88,6 +99,48 @@ func TestTableSchemaTargetName(t *testing.T) {
 			"",
 			true,
 		},
+		{
+			"by filter with illegal split value",
+			newTestTableSchema(DriverMysql, "xxx", testRecord{}),
+			SelectorFilter{"projectId": "1`; DROP TABLE xxx"},
+			"",
+			true,
+		},
+		{
+			"by filter comparing the split column",
+			newTestTableSchema(DriverMysql, "xxx", testRecord{}),
+			CompareFilter{Col: "projectId", Op: OpGe, Value: 1},
+			"",
+			true,
+		},
+		{
+			"by filters composed on the split column",
+			newTestTableSchema(DriverMysql, "xxx", testRecord{}),
+			RowFilterAnd{CompareFilter{Col: "projectId", Op: OpGe, Value: 1}, SelectorFilter{"projectId": 2}},
+			"xxx_2",
+			false,
+		},
+		{
+			"by custom filter",
+			newTestTableSchema(DriverMysql, "xxx", testRecord{}),
+			testProjectFilter{projectID: 3},
+			"xxx_3",
+			false,
+		},
+		{
+			"by custom filter composed",
+			newTestTableSchema(DriverMysql, "xxx", testRecord{}),
+			RowFilterAnd{SelectorFilter{"ruleId": 123}, testProjectFilter{projectID: 3}},
+			"xxx_3",
+			false,
+		},
+		{
+			"by like filter on the split column",
+			newTestTableSchema(DriverMysql, "xxx", testRecord{}),
+			LikeFilter{Key: "projectId", Value: "1%"},
+			"",
+			true,
+		},
 		{
 			"by not filter or struct ptr",
 			newTestTableSchema(DriverMysql, "xxx", testRecord{}),
@@ -136,7 +189,7 @@ func TestTableSchemaCreateSQL(t *testing.T) {
 			columns: []*ColSchema{{Name: "a", JSONName: "a", Type: "varchar(32)"}},
 			driver:  DriverMysql,
 			wantLines: []string{
-				"a varchar(32)",
+				"`a` varchar(32)",
 			},
 		},
 		{
@@ -145,7 +198,7 @@ func TestTableSchemaCreateSQL(t *testing.T) {
 
 			driver: DriverSQLite,
 			wantLines: []string{
-				"a varchar(32)",
+				"\"a\" varchar(32)",
 			},
 		},
 		{
@@ -153,7 +206,7 @@ func TestTableSchemaCreateSQL(t *testing.T) {
 			columns: []*ColSchema{{Name: "a", JSONName: "a", Type: "varchar(32)", Primary: true}},
 			driver:  DriverMysql,
 			wantLines: []string{
-				"a varchar(32) PRIMARY KEY",
+				"`a` varchar(32) PRIMARY KEY",
 			},
 		},
 		{
@@ -161,7 +214,7 @@ func TestTableSchemaCreateSQL(t *testing.T) {
 			columns: []*ColSchema{{Name: "a", JSONName: "a", Type: "varchar(32)", Primary: true}},
 			driver:  DriverSQLite,
 			wantLines: []string{
-				"a varchar(32) PRIMARY KEY",
+				"\"a\" varchar(32) PRIMARY KEY",
 			},
 		},
 		{
@@ -173,11 +226,11 @@ func TestTableSchemaCreateSQL(t *testing.T) {
 			},
 			driver: DriverMysql,
 			wantLines: []string{
-				"id INT NOT NULL AUTO_INCREMENT",
-				"a varchar(32)",
-				"b varchar(32)",
-				"PRIMARY KEY (a,b)",
-				"KEY id (id)",
+				"`id` INT NOT NULL AUTO_INCREMENT",
+				"`a` varchar(32)",
+				"`b` varchar(32)",
+				"PRIMARY KEY (`a`,`b`)",
+				"KEY `id` (`id`)",
 			},
 		},
 		{
@@ -185,7 +238,7 @@ func TestTableSchemaCreateSQL(t *testing.T) {
 			columns: []*ColSchema{{Name: "id", JSONName: "id", Type: "INT", AutoIncrement: true}},
 			driver:  DriverMysql,
 			wantLines: []string{
-				"id INT NOT NULL PRIMARY KEY AUTO_INCREMENT",
+				"`id` INT NOT NULL PRIMARY KEY AUTO_INCREMENT",
 			},
 		},
 		{
@@ -193,7 +246,7 @@ func TestTableSchemaCreateSQL(t *testing.T) {
 			columns: []*ColSchema{{Name: "id", JSONName: "id", Type: "INT", AutoIncrement: true}},
 			driver:  DriverSQLite,
 			wantLines: []string{
-				"id INTEGER PRIMARY KEY",
+				"\"id\" INTEGER PRIMARY KEY",
 			},
 		},
 		{
@@ -201,7 +254,7 @@ func TestTableSchemaCreateSQL(t *testing.T) {
 			columns: []*ColSchema{{Name: "id", JSONName: "id", Type: "INT", Unique: true}},
 			driver:  DriverMysql,
 			wantLines: []string{
-				"id INT UNIQUE KEY",
+				"`id` INT UNIQUE KEY",
 			},
 		},
 		{
@@ -209,7 +262,7 @@ func TestTableSchemaCreateSQL(t *testing.T) {
 			columns: []*ColSchema{{Name: "id", JSONName: "id", Type: "INT", Unique: true}},
 			driver:  DriverSQLite,
 			wantLines: []string{
-				"id INT UNIQUE",
+				"\"id\" INT UNIQUE",
 			},
 		},
 	}
@@ -221,7 +274,11 @@ func TestTableSchemaCreateSQL(t *testing.T) {
 				Name:    tableName,
 				Columns: tt.columns,
 			}
-			want := fmt.Sprintf(tableCreateSQLTpl, tableName, strings.Join(tt.wantLines, ",\n"))
+			dialect, err := GetDialect(tt.driver)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := fmt.Sprintf(tableCreateSQLTpl, dialect.Quote(tableName), strings.Join(tt.wantLines, ",\n"))
 
 			if got := s.CreateSQL(); got != want {
 				t.Errorf("TableSchema.CreateSQL() = %v, want %v", got, want)
@@ -229,3 +286,392 @@ func TestTableSchemaCreateSQL(t *testing.T) {
 		})
 	}
 }
+
+// testIndexRecord record with indexes declared in tags.
+type testIndexRecord struct {
+	ID        int64  `db:"id,type=BIGINT,primary"`
+	ProjectID int32  `db:"projectId,type=INT,index=idx_proj_time,unique_index=uk_proj_name:1"`
+	Name      string `db:"name,type=VARCHAR(32),unique_index=uk_proj_name:2"`
+	Time      int64  `db:"time,type=BIGINT,index=idx_proj_time:2|idx_time"`
+}
+
+func TestTableSchemaIndexes(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver string
+		want   []string
+	}{
+		{
+			"mysql",
+			DriverMysql,
+			[]string{"CREATE TABLE IF NOT EXISTS `test` (\n" +
+				"`id` BIGINT NOT NULL PRIMARY KEY,\n" +
+				"`projectId` INT,\n" +
+				"`name` VARCHAR(32),\n" +
+				"`time` BIGINT,\n" +
+				"KEY `idx_proj_time` (`projectId`,`time`),\n" +
+				"UNIQUE KEY `uk_proj_name` (`projectId`,`name`),\n" +
+				"KEY `idx_time` (`time`)\n)"},
+		},
+		{
+			"sqlite",
+			DriverSQLite3,
+			[]string{
+				"CREATE TABLE IF NOT EXISTS \"test\" (\n" +
+					"\"id\" BIGINT NOT NULL PRIMARY KEY,\n" +
+					"\"projectId\" INT,\n" +
+					"\"name\" VARCHAR(32),\n" +
+					"\"time\" BIGINT\n)",
+				`CREATE INDEX IF NOT EXISTS "test_idx_proj_time" ON "test" ("projectId","time")`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "test_uk_proj_name" ON "test" ("projectId","name")`,
+				`CREATE INDEX IF NOT EXISTS "test_idx_time" ON "test" ("time")`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestTableSchema(tt.driver, "test", testIndexRecord{})
+			if got, want := s.CreateSQL(), strings.Join(tt.want, sqlStatementSep); got != want {
+				t.Errorf("TableSchema.CreateSQL() = %v, want %v", got, want)
+			}
+		})
+	}
+
+	t.Run("unique conflicted", func(t *testing.T) {
+		s := &TableSchema{Driver: DriverMysql, Name: "test", Columns: []*ColSchema{
+			{Name: "a", Type: "INT", Indexes: []ColIndex{{Name: "idx_a_b"}}},
+			{Name: "b", Type: "INT", Indexes: []ColIndex{{Name: "idx_a_b", Unique: true}}},
+		}}
+		var errInvalid *ErrorSQLInvalid
+		if _, err := s.Indexes(); !errors.As(err, &errInvalid) {
+			t.Errorf("TableSchema.Indexes() error = %v, want ErrorSQLInvalid", err)
+		}
+	})
+}
+
+func Test_colSchemaIndexes(t *testing.T) {
+	tests := []struct {
+		option string
+		want   []ColIndex
+	}{
+		{"", nil},
+		{"idx_a", []ColIndex{{Name: "idx_a", Unique: true}}},
+		{"idx_a:2| idx_b", []ColIndex{{Name: "idx_a", Seq: 2, Unique: true}, {Name: "idx_b", Unique: true}}},
+		{"idx:x", []ColIndex{{Name: "idx:x", Unique: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.option, func(t *testing.T) {
+			if got := colSchemaIndexes(tt.option, true); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("colSchemaIndexes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type testFKRecord struct {
+	ID        int64  `db:"id,type=BIGINT,primary"`
+	RuleID    int64  `db:"ruleId,type=BIGINT,fk=rules(id),on_delete=cascade"`
+	ProjectID int64  `db:"projectId,type=BIGINT,fk=projects(id)"`
+	Message   string `db:"message,type=VARCHAR(64)"`
+}
+
+func TestTableSchemaForeignKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver string
+		want   string
+	}{
+		{
+			"mysql",
+			DriverMysql,
+			"CREATE TABLE IF NOT EXISTS `alerts` (\n" +
+				"`id` BIGINT NOT NULL PRIMARY KEY,\n" +
+				"`ruleId` BIGINT,\n" +
+				"`projectId` BIGINT,\n" +
+				"`message` VARCHAR(64),\n" +
+				"FOREIGN KEY (`ruleId`) REFERENCES `rules` (`id`) ON DELETE CASCADE,\n" +
+				"FOREIGN KEY (`projectId`) REFERENCES `projects` (`id`)\n)",
+		},
+		{
+			"sqlite",
+			DriverSQLite3,
+			"CREATE TABLE IF NOT EXISTS \"alerts\" (\n" +
+				"\"id\" BIGINT NOT NULL PRIMARY KEY,\n" +
+				"\"ruleId\" BIGINT,\n" +
+				"\"projectId\" BIGINT,\n" +
+				"\"message\" VARCHAR(64),\n" +
+				"FOREIGN KEY (\"ruleId\") REFERENCES \"rules\" (\"id\") ON DELETE CASCADE,\n" +
+				"FOREIGN KEY (\"projectId\") REFERENCES \"projects\" (\"id\")\n)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestTableSchema(tt.driver, "alerts", testFKRecord{})
+			if got := s.CreateSQL(); got != tt.want {
+				t.Errorf("TableSchema.CreateSQL() = %v, want %v", got, tt.want)
+			}
+			if got, want := s.RefTables(), []string{"rules", "projects"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("TableSchema.RefTables() = %v, want %v", got, want)
+			}
+		})
+	}
+
+	invalids := []struct {
+		name   string
+		column *ColSchema
+	}{
+		{"bad reference", &ColSchema{Name: "a", Type: "INT", ForeignKey: "rules.id"}},
+		{"bad on_delete", &ColSchema{Name: "a", Type: "INT", ForeignKey: "rules(id)", OnDelete: "drop"}},
+		{"on_delete without fk", &ColSchema{Name: "a", Type: "INT", OnDelete: "cascade"}},
+	}
+	for _, tt := range invalids {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &TableSchema{Driver: DriverMysql, Name: "test", Columns: []*ColSchema{tt.column}}
+			var errInvalid *ErrorSQLInvalid
+			if _, err := s.ForeignKeys(); !errors.As(err, &errInvalid) {
+				t.Errorf("TableSchema.ForeignKeys() error = %v, want ErrorSQLInvalid", err)
+			}
+			if got := s.CreateSQL(); got != "" {
+				t.Errorf("TableSchema.CreateSQL() = %v, want empty", got)
+			}
+		})
+	}
+}
+
+func TestTableSchemaPostgresSQL(t *testing.T) {
+	s := newTestTableSchema(DriverPostgres, "test", testRecord{})
+
+	t.Run("CreateSQL", func(t *testing.T) {
+		want := strings.Join([]string{
+			fmt.Sprintf(tableCreateSQLTpl, `"test"`, strings.Join([]string{
+				`"id" INTEGER NOT NULL GENERATED BY DEFAULT AS IDENTITY`,
+				`"projectId" INTEGER NOT NULL`,
+				`"ruleId" INTEGER NOT NULL`,
+				`"createtime" TIMESTAMP NOT NULL`,
+				`"sendStatus" SMALLINT DEFAULT 0`,
+				`"ensureUser" VARCHAR(32)`,
+				`"ensureStatus" SMALLINT DEFAULT 0`,
+				`"ensureTime" TIMESTAMP`,
+				`"title" VARCHAR(128) NOT NULL`,
+				`"body" VARCHAR(1024) NOT NULL`,
+				`PRIMARY KEY ("id","ruleId","createtime")`,
+			}, ",\n")),
+		}, sqlStatementSep)
+
+		if got := s.CreateSQL(); got != want {
+			t.Errorf("TableSchema.CreateSQL() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("CreateSQL with key index", func(t *testing.T) {
+		keySchema := &TableSchema{
+			Driver: DriverPostgres,
+			Name:   "test",
+			Columns: []*ColSchema{
+				{Name: "id", Type: "BIGINT", AutoIncrement: true},
+				{Name: "a", Type: "varchar(32)", Primary: true},
+			},
+		}
+		want := strings.Join([]string{
+			fmt.Sprintf(tableCreateSQLTpl, `"test"`, "\"id\" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,\n\"a\" varchar(32) PRIMARY KEY"),
+			`CREATE INDEX IF NOT EXISTS "test_id" ON "test" ("id")`,
+		}, sqlStatementSep)
+
+		if got := keySchema.CreateSQL(); got != want {
+			t.Errorf("TableSchema.CreateSQL() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("InsertSQL", func(t *testing.T) {
+		want := `INSERT INTO "test_1" ("projectId","ruleId","createtime","title","body") ` +
+			`VALUES (:projectId,:ruleId,:createtime,:title,:body) RETURNING "id"`
+		if got := s.InsertSQL("test_1"); got != want {
+			t.Errorf("TableSchema.InsertSQL() = %v, want %v", got, want)
+		}
+
+		// bind style of postgresql.
+		bound, args, err := sqlx.BindNamed(sqlx.BindType(DriverPostgres), want, &testRecord{ProjectID: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantBound := `INSERT INTO "test_1" ("projectId","ruleId","createtime","title","body") VALUES ($1,$2,$3,$4,$5) RETURNING "id"`
+		if bound != wantBound || len(args) != 5 {
+			t.Errorf("sqlx.BindNamed() = %v, %v, want %v", bound, args, wantBound)
+		}
+	})
+
+	t.Run("BatchInsertSQL", func(t *testing.T) {
+		want := `INSERT INTO "test_1" ("projectId","ruleId","createtime","title","body") ` +
+			`VALUES (:projectId_0,:ruleId_0,:createtime_0,:title_0,:body_0),` +
+			`(:projectId_1,:ruleId_1,:createtime_1,:title_1,:body_1) RETURNING "id"`
+		if got := s.BatchInsertSQL("test_1", 2); got != want {
+			t.Errorf("TableSchema.BatchInsertSQL() = %v, want %v", got, want)
+		}
+		if got := s.BatchInsertSQL("test_1", 0); got != "" {
+			t.Errorf("TableSchema.BatchInsertSQL() with zero rows = %v, want empty", got)
+		}
+	})
+
+	t.Run("UpsertSQL", func(t *testing.T) {
+		want := `INSERT INTO "test_1" ("projectId","ruleId","createtime","title","body") ` +
+			`VALUES (:projectId,:ruleId,:createtime,:title,:body) ` +
+			`ON CONFLICT ("id","ruleId","createtime") DO UPDATE SET ` +
+			`"sendStatus"=EXCLUDED."sendStatus","ensureUser"=EXCLUDED."ensureUser","ensureStatus"=EXCLUDED."ensureStatus",` +
+			`"ensureTime"=EXCLUDED."ensureTime","title"=EXCLUDED."title","body"=EXCLUDED."body" RETURNING "id"`
+		got, err := s.UpsertSQL("test_1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("TableSchema.UpsertSQL() = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestTableSchemaUpsertSQL(t *testing.T) {
+	tests := []struct {
+		name    string
+		driver  string
+		columns []*ColSchema
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "mysql",
+			driver: DriverMysql,
+			columns: []*ColSchema{
+				{Name: "a", Type: "INT", Primary: true},
+				{Name: "b", Type: "INT"},
+			},
+			want: "INSERT INTO `test` (`a`,`b`) VALUES (:a,:b) ON DUPLICATE KEY UPDATE `b`=:b",
+		},
+		{
+			name:   "mysql - version col",
+			driver: DriverMysql,
+			columns: []*ColSchema{
+				{Name: "a", Type: "INT", Primary: true},
+				{Name: "b", Type: "INT"},
+				{Name: "v", Type: "INT", Version: true},
+			},
+			want: "INSERT INTO `test` (`a`,`b`,`v`) VALUES (:a,:b,:v) ON DUPLICATE KEY UPDATE `b`=:b,`v`=`v`+1",
+		},
+		{
+			name:   "postgres - version col",
+			driver: DriverPostgres,
+			columns: []*ColSchema{
+				{Name: "a", Type: "INT", Primary: true},
+				{Name: "b", Type: "INT"},
+				{Name: "v", Type: "INT", Version: true},
+			},
+			want: `INSERT INTO "test" ("a","b","v") VALUES (:a,:b,:v) ON CONFLICT ("a") DO UPDATE SET "b"=EXCLUDED."b","v"="test"."v"+1`,
+		},
+		{
+			name:    "mysql - no update cols",
+			driver:  DriverMysql,
+			columns: []*ColSchema{{Name: "a", Type: "INT", Primary: true}},
+			want:    "INSERT INTO `test` (`a`) VALUES (:a) ON DUPLICATE KEY UPDATE `a`=`a`",
+		},
+		{
+			name:    "mysql - no keys and update cols",
+			driver:  DriverMysql,
+			columns: []*ColSchema{{Name: "a", Type: "INT", NotUpdate: true}},
+			wantErr: true,
+		},
+		{
+			name:    "postgres - unique col",
+			driver:  DriverPostgres,
+			columns: []*ColSchema{{Name: "a", Type: "INT", Unique: true}},
+			want:    `INSERT INTO "test" ("a") VALUES (:a) ON CONFLICT ("a") DO NOTHING`,
+		},
+		{
+			name:    "postgres - no keys",
+			driver:  DriverPostgres,
+			columns: []*ColSchema{{Name: "a", Type: "INT"}},
+			wantErr: true,
+		},
+		{
+			name:    "not supported driver",
+			driver:  "xxx",
+			columns: []*ColSchema{{Name: "a", Type: "INT"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &TableSchema{Driver: tt.driver, Name: "test", Columns: tt.columns}
+			got, err := s.UpsertSQL("test")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TableSchema.UpsertSQL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("TableSchema.UpsertSQL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableSchemaSelectSQL(t *testing.T) {
+	s := newTestTableSchema(DriverSQLite3, "test", testSimpleRecord{})
+
+	tests := []struct {
+		name    string
+		options ListOptions
+		want    string
+		wantErr bool
+	}{
+		{
+			"order by column",
+			ListOptions{AllColumns: true, OrderByColumn: "score", OrderDesc: true},
+			`select  * from "test" ORDER BY "score" DESC`,
+			false,
+		},
+		{
+			"order by terms",
+			ListOptions{
+				AllColumns:    true,
+				OrderByColumn: "id",
+				OrderBy:       []OrderTerm{{Col: "score", Desc: true}, {Col: "name", NullsFirst: true}},
+			},
+			`select  * from "test" ORDER BY "score" DESC, "name" IS NULL DESC, "name"`,
+			false,
+		},
+		{"unknown order column", ListOptions{OrderByColumn: "score; DROP TABLE test"}, "", true},
+		{"unknown order term", ListOptions{OrderBy: []OrderTerm{{Col: "id"}, {Col: "x"}}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := s.SelectSQL(nil, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TableSchema.SelectSQL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("TableSchema.SelectSQL() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTableSchemaPrimaryCols_notChangeSchema(t *testing.T) {
+	for _, driver := range []string{DriverMysql, DriverSQLite3, DriverPostgres} {
+		t.Run(driver, func(t *testing.T) {
+			s := newTestTableSchema(driver, "test", testSimpleRecord{})
+			for i := 0; i < 2; i++ {
+				if got, err := s.PrimaryCols(); err != nil || !reflect.DeepEqual(got, []string{"id"}) {
+					t.Errorf("TableSchema.PrimaryCols() = %v, %v, want [id]", got, err)
+				}
+				if got := s.AutoIncrementCol(); got != "id" {
+					t.Errorf("TableSchema.AutoIncrementCol() = %v, want id", got)
+				}
+				if got := s.InsertCols(); !reflect.DeepEqual(got, []string{"name", "score"}) {
+					t.Errorf("TableSchema.InsertCols() = %v, want [name score]", got)
+				}
+				if got := s.KeyCol(); got != "" {
+					t.Errorf("TableSchema.KeyCol() = %v, want empty", got)
+				}
+			}
+		})
+	}
+}
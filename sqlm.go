@@ -2,6 +2,8 @@
 package sqlm
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 	"sort"
 	"sync"
@@ -16,8 +18,9 @@ var (
 	// database create drivers
 	createDriversMu sync.RWMutex
 	createDrivers   = map[string]DatabaseCreator{
-		"mysql":   new(mysqlCreateImp),
-		"sqlite3": new(sqlite3CreateImp),
+		"mysql":    new(mysqlCreateImp),
+		"sqlite3":  new(sqlite3CreateImp),
+		"postgres": new(postgresCreateImp),
 	}
 )
 
@@ -29,12 +32,32 @@ type TableAble interface {
 	Create() error
 	Insert(interface{}) (int64, error)
 	Inserts([]interface{}) ([]int64, error)
+	Upsert(interface{}) (int64, error)
+	Upserts([]interface{}) ([]int64, error)
 	Save(interface{}) error
 	Update(RowFilter, map[string]interface{}) error
 	Delete(RowFilter) error
 	Get(RowFilter, interface{}) error
 	List(RowFilter, ListOptions) ([]interface{}, error)
+	ListPage(RowFilter, ListOptions) ([]interface{}, string, error)
+	Count(RowFilter) (int64, error)
+	Exists(RowFilter) (bool, error)
 	IsDup(interface{}) (interface{}, error)
+
+	CreateContext(context.Context) error
+	InsertContext(context.Context, interface{}) (int64, error)
+	InsertsContext(context.Context, []interface{}) ([]int64, error)
+	UpsertContext(context.Context, interface{}) (int64, error)
+	UpsertsContext(context.Context, []interface{}) ([]int64, error)
+	SaveContext(context.Context, interface{}) error
+	UpdateContext(context.Context, RowFilter, map[string]interface{}) error
+	DeleteContext(context.Context, RowFilter) error
+	GetContext(context.Context, RowFilter, interface{}) error
+	ListContext(context.Context, RowFilter, ListOptions) ([]interface{}, error)
+	ListPageContext(context.Context, RowFilter, ListOptions) ([]interface{}, string, error)
+	CountContext(context.Context, RowFilter) (int64, error)
+	ExistsContext(context.Context, RowFilter) (bool, error)
+	IsDupContext(context.Context, interface{}) (interface{}, error)
 }
 
 type dbOptionSetter func(*sqlx.DB)
@@ -77,12 +100,27 @@ func DBCreateDrivers() []string {
 	return list
 }
 
-// DBCreateIterStructField 遍历配置模型的各个配置属性创建数据表.
+// DBCreateIterStructField 遍历配置模型的各个配置属性创建数据表, 被外键引用的表先创建.
 func DBCreateIterStructField(val reflect.Value, optionSetter dbOptionSetter) error {
+	var tables []TableAble
+	for i := 0; i < val.NumField(); i++ {
+		if table := reflectTable(val.Field(i)); table != nil {
+			tables = append(tables, table)
+		}
+	}
+
+	tables, err := sortTablesByRefs(tables)
+	if err != nil {
+		return err
+	}
+
 	var dbCons []*sqlx.DB
+	for _, table := range tables {
+		if err := table.Create(); err != nil {
+			return err
+		}
 
-	for i := 0; i < val.NumField(); i++ {
-		dbCon, err := createReflectTable(val.Field(i))
+		dbCon, err := table.Con()
 		if err != nil {
 			return err
 		}
@@ -103,20 +141,68 @@ func DBCreateIterStructField(val reflect.Value, optionSetter dbOptionSetter) err
 	return nil
 }
 
-// createReflectTable 依据数据表反射值进行创建.
-func createReflectTable(vf reflect.Value) (*sqlx.DB, error) {
+// reflectTable 返回反射值中的数据表, 不是数据表时返回 nil.
+func reflectTable(vf reflect.Value) TableAble {
 	if vf.IsNil() || !vf.CanInterface() {
-		return nil, nil
+		return nil
 	}
 
-	table, ok := vf.Interface().(TableAble)
-	if !ok {
-		return nil, nil
+	table, _ := vf.Interface().(TableAble)
+	return table
+}
+
+// sortTablesByRefs 按外键依赖排序, 被引用的表在前, 无依赖关系的表保持原有顺序.
+// 只有 *Table 能解析出外键, 引用不在列表中的表时忽略.
+func sortTablesByRefs(tables []TableAble) ([]TableAble, error) {
+	indexOfName := make(map[string]int)
+	refsOf := make([][]string, len(tables))
+	for i, table := range tables {
+		t, ok := table.(*Table)
+		if !ok {
+			continue
+		}
+		indexOfName[t.TableName] = i
+		if schema := t.getSchema(); schema != nil {
+			refsOf[i] = schema.RefTables()
+		}
 	}
 
-	if err := table.Create(); err != nil {
-		return nil, err
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(tables))
+	ret := make([]TableAble, 0, len(tables))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch states[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("foreign key cycle found at table %s", tables[i].(*Table).TableName)
+		}
+
+		states[i] = visiting
+		for _, ref := range refsOf[i] {
+			if j, ok := indexOfName[ref]; ok {
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+		states[i] = visited
+		ret = append(ret, tables[i])
+
+		return nil
+	}
+
+	for i := range tables {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
 	}
 
-	return table.Con()
+	return ret, nil
 }
@@ -4,10 +4,10 @@
 package sqlm
 
 import (
-	reflect "reflect"
-
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	sqlx "github.com/jmoiron/sqlx"
+	reflect "reflect"
 )
 
 // MockTableAble is a mock of TableAble interface.
@@ -48,6 +48,36 @@ func (mr *MockTableAbleMockRecorder) Con() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Con", reflect.TypeOf((*MockTableAble)(nil).Con))
 }
 
+// Count mocks base method.
+func (m *MockTableAble) Count(arg0 RowFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockTableAbleMockRecorder) Count(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockTableAble)(nil).Count), arg0)
+}
+
+// CountContext mocks base method.
+func (m *MockTableAble) CountContext(arg0 context.Context, arg1 RowFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountContext", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountContext indicates an expected call of CountContext.
+func (mr *MockTableAbleMockRecorder) CountContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountContext", reflect.TypeOf((*MockTableAble)(nil).CountContext), arg0, arg1)
+}
+
 // Create mocks base method.
 func (m *MockTableAble) Create() error {
 	m.ctrl.T.Helper()
@@ -62,6 +92,20 @@ func (mr *MockTableAbleMockRecorder) Create() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTableAble)(nil).Create))
 }
 
+// CreateContext mocks base method.
+func (m *MockTableAble) CreateContext(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateContext", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateContext indicates an expected call of CreateContext.
+func (mr *MockTableAbleMockRecorder) CreateContext(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateContext", reflect.TypeOf((*MockTableAble)(nil).CreateContext), arg0)
+}
+
 // Delete mocks base method.
 func (m *MockTableAble) Delete(arg0 RowFilter) error {
 	m.ctrl.T.Helper()
@@ -76,6 +120,50 @@ func (mr *MockTableAbleMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTableAble)(nil).Delete), arg0)
 }
 
+// DeleteContext mocks base method.
+func (m *MockTableAble) DeleteContext(arg0 context.Context, arg1 RowFilter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteContext", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteContext indicates an expected call of DeleteContext.
+func (mr *MockTableAbleMockRecorder) DeleteContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteContext", reflect.TypeOf((*MockTableAble)(nil).DeleteContext), arg0, arg1)
+}
+
+// Exists mocks base method.
+func (m *MockTableAble) Exists(arg0 RowFilter) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exists", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exists indicates an expected call of Exists.
+func (mr *MockTableAbleMockRecorder) Exists(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockTableAble)(nil).Exists), arg0)
+}
+
+// ExistsContext mocks base method.
+func (m *MockTableAble) ExistsContext(arg0 context.Context, arg1 RowFilter) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExistsContext", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExistsContext indicates an expected call of ExistsContext.
+func (mr *MockTableAbleMockRecorder) ExistsContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExistsContext", reflect.TypeOf((*MockTableAble)(nil).ExistsContext), arg0, arg1)
+}
+
 // Get mocks base method.
 func (m *MockTableAble) Get(arg0 RowFilter, arg1 interface{}) error {
 	m.ctrl.T.Helper()
@@ -90,6 +178,20 @@ func (mr *MockTableAbleMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTableAble)(nil).Get), arg0, arg1)
 }
 
+// GetContext mocks base method.
+func (m *MockTableAble) GetContext(arg0 context.Context, arg1 RowFilter, arg2 interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetContext indicates an expected call of GetContext.
+func (mr *MockTableAbleMockRecorder) GetContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContext", reflect.TypeOf((*MockTableAble)(nil).GetContext), arg0, arg1, arg2)
+}
+
 // Insert mocks base method.
 func (m *MockTableAble) Insert(arg0 interface{}) (int64, error) {
 	m.ctrl.T.Helper()
@@ -105,6 +207,21 @@ func (mr *MockTableAbleMockRecorder) Insert(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockTableAble)(nil).Insert), arg0)
 }
 
+// InsertContext mocks base method.
+func (m *MockTableAble) InsertContext(arg0 context.Context, arg1 interface{}) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertContext", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertContext indicates an expected call of InsertContext.
+func (mr *MockTableAbleMockRecorder) InsertContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertContext", reflect.TypeOf((*MockTableAble)(nil).InsertContext), arg0, arg1)
+}
+
 // Inserts mocks base method.
 func (m *MockTableAble) Inserts(arg0 []interface{}) ([]int64, error) {
 	m.ctrl.T.Helper()
@@ -120,6 +237,21 @@ func (mr *MockTableAbleMockRecorder) Inserts(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Inserts", reflect.TypeOf((*MockTableAble)(nil).Inserts), arg0)
 }
 
+// InsertsContext mocks base method.
+func (m *MockTableAble) InsertsContext(arg0 context.Context, arg1 []interface{}) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertsContext", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertsContext indicates an expected call of InsertsContext.
+func (mr *MockTableAbleMockRecorder) InsertsContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertsContext", reflect.TypeOf((*MockTableAble)(nil).InsertsContext), arg0, arg1)
+}
+
 // IsDup mocks base method.
 func (m *MockTableAble) IsDup(arg0 interface{}) (interface{}, error) {
 	m.ctrl.T.Helper()
@@ -135,6 +267,21 @@ func (mr *MockTableAbleMockRecorder) IsDup(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsDup", reflect.TypeOf((*MockTableAble)(nil).IsDup), arg0)
 }
 
+// IsDupContext mocks base method.
+func (m *MockTableAble) IsDupContext(arg0 context.Context, arg1 interface{}) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsDupContext", arg0, arg1)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsDupContext indicates an expected call of IsDupContext.
+func (mr *MockTableAbleMockRecorder) IsDupContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsDupContext", reflect.TypeOf((*MockTableAble)(nil).IsDupContext), arg0, arg1)
+}
+
 // List mocks base method.
 func (m *MockTableAble) List(arg0 RowFilter, arg1 ListOptions) ([]interface{}, error) {
 	m.ctrl.T.Helper()
@@ -150,6 +297,53 @@ func (mr *MockTableAbleMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTableAble)(nil).List), arg0, arg1)
 }
 
+// ListContext mocks base method.
+func (m *MockTableAble) ListContext(arg0 context.Context, arg1 RowFilter, arg2 ListOptions) ([]interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListContext indicates an expected call of ListContext.
+func (mr *MockTableAbleMockRecorder) ListContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListContext", reflect.TypeOf((*MockTableAble)(nil).ListContext), arg0, arg1, arg2)
+}
+
+// ListPage mocks base method.
+func (m *MockTableAble) ListPage(arg0 RowFilter, arg1 ListOptions) ([]interface{}, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPage", arg0, arg1)
+	ret0, _ := ret[0].([]interface{})
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListPage indicates an expected call of ListPage.
+func (mr *MockTableAbleMockRecorder) ListPage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPage", reflect.TypeOf((*MockTableAble)(nil).ListPage), arg0, arg1)
+}
+
+// ListPageContext mocks base method.
+func (m *MockTableAble) ListPageContext(arg0 context.Context, arg1 RowFilter, arg2 ListOptions) ([]interface{}, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPageContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]interface{})
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListPageContext indicates an expected call of ListPageContext.
+func (mr *MockTableAbleMockRecorder) ListPageContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPageContext", reflect.TypeOf((*MockTableAble)(nil).ListPageContext), arg0, arg1, arg2)
+}
+
 // RowModel mocks base method.
 func (m *MockTableAble) RowModel() interface{} {
 	m.ctrl.T.Helper()
@@ -178,6 +372,20 @@ func (mr *MockTableAbleMockRecorder) Save(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockTableAble)(nil).Save), arg0)
 }
 
+// SaveContext mocks base method.
+func (m *MockTableAble) SaveContext(arg0 context.Context, arg1 interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveContext", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveContext indicates an expected call of SaveContext.
+func (mr *MockTableAbleMockRecorder) SaveContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveContext", reflect.TypeOf((*MockTableAble)(nil).SaveContext), arg0, arg1)
+}
+
 // SetRowModel mocks base method.
 func (m *MockTableAble) SetRowModel(arg0 func() interface{}) {
 	m.ctrl.T.Helper()
@@ -203,3 +411,77 @@ func (mr *MockTableAbleMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTableAble)(nil).Update), arg0, arg1)
 }
+
+// UpdateContext mocks base method.
+func (m *MockTableAble) UpdateContext(arg0 context.Context, arg1 RowFilter, arg2 map[string]interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateContext indicates an expected call of UpdateContext.
+func (mr *MockTableAbleMockRecorder) UpdateContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateContext", reflect.TypeOf((*MockTableAble)(nil).UpdateContext), arg0, arg1, arg2)
+}
+
+// Upsert mocks base method.
+func (m *MockTableAble) Upsert(arg0 interface{}) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockTableAbleMockRecorder) Upsert(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockTableAble)(nil).Upsert), arg0)
+}
+
+// UpsertContext mocks base method.
+func (m *MockTableAble) UpsertContext(arg0 context.Context, arg1 interface{}) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertContext", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertContext indicates an expected call of UpsertContext.
+func (mr *MockTableAbleMockRecorder) UpsertContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertContext", reflect.TypeOf((*MockTableAble)(nil).UpsertContext), arg0, arg1)
+}
+
+// Upserts mocks base method.
+func (m *MockTableAble) Upserts(arg0 []interface{}) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upserts", arg0)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upserts indicates an expected call of Upserts.
+func (mr *MockTableAbleMockRecorder) Upserts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upserts", reflect.TypeOf((*MockTableAble)(nil).Upserts), arg0)
+}
+
+// UpsertsContext mocks base method.
+func (m *MockTableAble) UpsertsContext(arg0 context.Context, arg1 []interface{}) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertsContext", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertsContext indicates an expected call of UpsertsContext.
+func (mr *MockTableAbleMockRecorder) UpsertsContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertsContext", reflect.TypeOf((*MockTableAble)(nil).UpsertsContext), arg0, arg1)
+}
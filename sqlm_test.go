@@ -54,6 +54,78 @@ func TestDBCreateIterStructField(t *testing.T) {
 	})
 }
 
+type testRuleRecord struct {
+	ID   int64  `db:"id,type=INTEGER,primary"`
+	Name string `db:"name,type=VARCHAR(32)"`
+}
+
+type testAlertRecord struct {
+	ID     int64 `db:"id,type=INTEGER,primary"`
+	RuleID int64 `db:"ruleId,type=INTEGER,not_null,fk=test_rules(id),on_delete=cascade"`
+}
+
+func TestDBCreateIterStructField_foreignKeys(t *testing.T) {
+	db := &Database{Driver: DriverSQLite3, DSN: fmt.Sprintf("file:%s/fk.db", t.TempDir())}
+	alerts := &Table{Database: db, TableName: "test_alerts"}
+	alerts.SetRowModel(func() interface{} { return &testAlertRecord{} })
+	rules := &Table{Database: db, TableName: "test_rules"}
+	rules.SetRowModel(func() interface{} { return &testRuleRecord{} })
+
+	// the referencing table is declared before the referenced one.
+	dbGroup := struct {
+		Alerts TableAble
+		Rules  TableAble
+	}{alerts, rules}
+	if err := DBCreateIterStructField(reflect.ValueOf(dbGroup), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rules.Insert(&testRuleRecord{ID: 1, Name: "r"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := alerts.Insert(&testAlertRecord{ID: 1, RuleID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := alerts.Insert(&testAlertRecord{ID: 2, RuleID: 2}); err == nil {
+		t.Errorf("Table.Insert() with missing referenced row should fail")
+	}
+
+	if err := rules.Delete(SelectorFilter{"id": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := alerts.Count(nil); err != nil || count != 0 {
+		t.Errorf("Table.Count() after cascade delete = %v, %v, want 0", count, err)
+	}
+}
+
+func Test_sortTablesByRefs(t *testing.T) {
+	newTable := func(name string, model interface{}) *Table {
+		table := &Table{Database: &Database{Driver: DriverSQLite3}, TableName: name}
+		table.SetRowModel(func() interface{} { return model })
+		return table
+	}
+
+	rules := newTable("test_rules", &testRuleRecord{})
+	alerts := newTable("test_alerts", &testAlertRecord{})
+	other := newTable("test_other", &testRecord{})
+
+	got, err := sortTablesByRefs([]TableAble{alerts, other, rules})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []TableAble{rules, alerts, other}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sortTablesByRefs() = %v, want %v", got, want)
+	}
+
+	type testCycleRecord struct {
+		ID int64 `db:"id,type=INTEGER,primary,fk=test_alerts(id)"`
+	}
+	cycled := newTable("test_rules", &testCycleRecord{})
+	if _, err := sortTablesByRefs([]TableAble{alerts, cycled}); err == nil {
+		t.Errorf("sortTablesByRefs() with cycle should fail")
+	}
+}
+
 func testDBConOptionSet(con *sqlx.DB) {
 	con.SetMaxIdleConns(10)
 }
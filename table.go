@@ -1,14 +1,16 @@
 package sqlm
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
 	"sync"
 )
 
-// TableNotExistErrorRegex for table not exist db response
-const TableNotExistErrorRegex = `[tT]able\s+.+\s+doesn't\s+exist`
+// TableNotExistErrorRegex for table not exist db response, it's used when no dialect registered for the driver,
+// such as mysql: "Table 'db.xxx' doesn't exist", postgresql: `relation "xxx" does not exist`, sqlite: "no such table: xxx".
+const TableNotExistErrorRegex = `[tT]able\s+.+\s+doesn't\s+exist|relation\s+.+\s+does\s+not\s+exist|no\s+such\s+table`
 
 // SQL 关键字.
 //
@@ -35,9 +37,51 @@ type ListOptions struct {
 	Columns       []string
 	OrderByColumn string
 	OrderDesc     bool
+	OrderBy       []OrderTerm // sort keys, it takes precedence over OrderByColumn and OrderDesc.
 	AllColumns    bool
 	Distinct      bool
 	Limit         int32
+	Offset        int32
+	Cursor        string // opaque cursor token returned by Table#ListPage() for resuming with keyset pagination.
+	// Preload names of the relations declared by Table#HasOne() and Table#HasMany(), the related records
+	// are queried in batch after listing and attached to the fields tagged with `preload:"<name>"`.
+	Preload []string
+	// Deleted scope of the soft deleted records, they are excluded by default.
+	Deleted DeletedScope
+}
+
+// DeletedScope scope of the soft deleted records in listing.
+type DeletedScope int
+
+// scopes of the soft deleted records.
+const (
+	DeletedExcluded DeletedScope = iota // only the records not deleted.
+	DeletedIncluded                     // all records including the soft deleted ones.
+	DeletedOnly                         // only the soft deleted records.
+)
+
+// WithDeleted return the options listing the soft deleted records too.
+func (o ListOptions) WithDeleted() ListOptions {
+	o.Deleted = DeletedIncluded
+	return o
+}
+
+// OnlyDeleted return the options listing the soft deleted records only.
+func (o ListOptions) OnlyDeleted() ListOptions {
+	o.Deleted = DeletedOnly
+	return o
+}
+
+// orderTerms return the sort keys of options.
+func (o *ListOptions) orderTerms() []OrderTerm {
+	if len(o.OrderBy) > 0 {
+		return o.OrderBy
+	}
+	if o.OrderByColumn != "" {
+		return []OrderTerm{{Col: o.OrderByColumn, Desc: o.OrderDesc}}
+	}
+
+	return nil
 }
 
 // Table Sql Table
@@ -45,8 +89,19 @@ type Table struct {
 	*Database  `json:"database"`
 	TableName  string `json:"tableName"`
 	TableHooks `json:"-"`
+	// InsertBatchSize the maximum rows in one inserting statement of Inserts(), default is 500,
+	// it's reduced further by the bound variables and packet size limits of the dialect.
+	// 1 means inserting records one by one.
+	InsertBatchSize int `json:"insertBatchSize,omitempty"`
+	// FanOutConcurrency the maximum shards queried at the same time when listing or counting across
+	// the split tables, default is 4. Shards are queried one by one in transaction.
+	// Ordered iterating queries all shards at the same time, so it fails when the shards are more than it.
+	FanOutConcurrency int `json:"fanOutConcurrency,omitempty"`
+
 	schema     *TableSchema
 	rowModeler func() interface{}
+	tx         *Tx
+	relations  map[string]Relation
 
 	once sync.Once
 }
@@ -67,17 +122,22 @@ func (t *Table) SetRowModel(modeler func() interface{}) {
 
 // Create table if not exists
 func (t *Table) Create() error {
+	return t.CreateContext(context.Background())
+}
+
+// CreateContext create table if not exists with context.
+func (t *Table) CreateContext(ctx context.Context) error {
 	if err := t.Database.Create(); err != nil {
 		return err
 	}
 
-	con, err := t.Con()
+	ext, err := t.ddlExecutor()
 	if err != nil {
 		return err
 	}
 
 	createSQL := t.getSchema().CreateSQL()
-	_, err = con.Exec(createSQL)
+	_, err = ext.ExecContext(ctx, createSQL)
 	if err != nil {
 		return fmt.Errorf("%w\n sql: %s", err, createSQL)
 	}
@@ -87,23 +147,24 @@ func (t *Table) Create() error {
 
 // Insert records to table.
 func (t *Table) Insert(record interface{}) (int64, error) {
+	return t.InsertContext(context.Background(), record)
+}
+
+// InsertContext insert record to table with context.
+func (t *Table) InsertContext(ctx context.Context, record interface{}) (int64, error) {
 	// call before hooks.
-	for _, hook := range t.TableHooks.Insert.Before {
-		if err := hook.(InsertHookFunc)(t, record); err != nil {
-			return 0, err
-		}
+	if err := callRecordHooks(ctx, t.TableHooks.Insert.Before, t, record); err != nil {
+		return 0, err
 	}
 
-	insertID, err := t.insert(record)
+	insertID, err := t.insert(ctx, record)
 	if err != nil {
 		return insertID, err
 	}
 
 	// call after hooks.
-	for _, hook := range t.TableHooks.Insert.After {
-		if hookErr := hook.(InsertHookFunc)(t, record); hookErr != nil {
-			return insertID, hookErr
-		}
+	if hookErr := callRecordHooks(ctx, t.TableHooks.Insert.After, t, record); hookErr != nil {
+		return insertID, hookErr
 	}
 
 	return insertID, err
@@ -111,22 +172,32 @@ func (t *Table) Insert(record interface{}) (int64, error) {
 
 // IsDup record in table
 func (t *Table) IsDup(row interface{}) (interface{}, error) {
+	return t.IsDupContext(context.Background(), row)
+}
+
+// IsDupContext find the record with same primary keys in table with context.
+func (t *Table) IsDupContext(ctx context.Context, row interface{}) (interface{}, error) {
 	whereFormatter := t.uniqWhereFormatter()
 	targetTable, err := t.getSchema().TargetName(row)
 	if err != nil {
 		return nil, err
 	}
-	query := fmt.Sprintf("select %s from %s", strings.Join(t.getSchema().ColNames(true), ","), targetTable)
+	schema := t.getSchema()
+	query := fmt.Sprintf("select %s from %s", strings.Join(schema.quotes(schema.ColNames(true)), ","), schema.quoteTable(targetTable))
 	if whereFormatter != "" {
 		query += " where " + whereFormatter
+		// 软删除的记录不算重复
+		if col := schema.SoftDeleteCol(); col != "" {
+			query += " AND " + schema.quote(col) + " IS NULL"
+		}
 	}
 
-	con, err := t.Con()
+	ext, err := t.executor()
 	if err != nil {
 		return false, err
 	}
 
-	rows, queryErr := con.NamedQuery(query, row)
+	rows, queryErr := t.namedQuery(ctx, ext, query, row)
 	if queryErr != nil || rows == nil {
 		return false, queryErr
 	}
@@ -155,25 +226,79 @@ func (t *Table) IsDup(row interface{}) (interface{}, error) {
 	}
 }
 
-// Inserts records to Table
+// Inserts records to Table, the ids of records inserted in multi-row statements are zero when
+// the table has no auto increment column or they can not be inferred by dialect.
 func (t *Table) Inserts(records []interface{}) ([]int64, error) {
+	return t.InsertsContext(context.Background(), records)
+}
+
+// InsertsContext insert records to Table with context.
+func (t *Table) InsertsContext(ctx context.Context, records []interface{}) ([]int64, error) {
 	// call before hooks
-	for _, hook := range t.TableHooks.Inserts.Before {
-		if err := hook.(InsertsHookFunc)(t, records); err != nil {
-			return nil, err
-		}
+	if err := callInsertsHooks(ctx, t.TableHooks.Inserts.Before, t, records); err != nil {
+		return nil, err
 	}
 
-	ret, err := t.inserts(records)
+	ret, err := t.inserts(ctx, records)
 	if err != nil {
 		return ret, err
 	}
 
 	// call after hooks
-	for _, hook := range t.TableHooks.Inserts.After {
-		if hookErr := hook.(InsertsHookFunc)(t, records); hookErr != nil {
-			return ret, hookErr
-		}
+	if hookErr := callInsertsHooks(ctx, t.TableHooks.Inserts.After, t, records); hookErr != nil {
+		return ret, hookErr
+	}
+
+	return ret, err
+}
+
+// Upsert insert record to table, the exist record with same primary or unique keys is updated instead.
+// The id of inserted record is returned, it's zero when the exist record is updated in mysql,
+// but sqlite can not tell them apart, and the LastInsertId of connection is returned.
+func (t *Table) Upsert(record interface{}) (int64, error) {
+	return t.UpsertContext(context.Background(), record)
+}
+
+// UpsertContext upsert record to table with context.
+func (t *Table) UpsertContext(ctx context.Context, record interface{}) (int64, error) {
+	// call before hooks.
+	if err := callRecordHooks(ctx, t.TableHooks.Upsert.Before, t, record); err != nil {
+		return 0, err
+	}
+
+	insertID, err := t.upsert(ctx, record)
+	if err != nil {
+		return insertID, err
+	}
+
+	// call after hooks.
+	if hookErr := callRecordHooks(ctx, t.TableHooks.Upsert.After, t, record); hookErr != nil {
+		return insertID, hookErr
+	}
+
+	return insertID, err
+}
+
+// Upserts upsert records to Table
+func (t *Table) Upserts(records []interface{}) ([]int64, error) {
+	return t.UpsertsContext(context.Background(), records)
+}
+
+// UpsertsContext upsert records to Table with context.
+func (t *Table) UpsertsContext(ctx context.Context, records []interface{}) ([]int64, error) {
+	// call before hooks
+	if err := callInsertsHooks(ctx, t.TableHooks.Upserts.Before, t, records); err != nil {
+		return nil, err
+	}
+
+	ret, err := t.upserts(ctx, records)
+	if err != nil {
+		return ret, err
+	}
+
+	// call after hooks
+	if hookErr := callInsertsHooks(ctx, t.TableHooks.Upserts.After, t, records); hookErr != nil {
+		return ret, hookErr
 	}
 
 	return ret, err
@@ -181,39 +306,39 @@ func (t *Table) Inserts(records []interface{}) ([]int64, error) {
 
 // Save the exist record
 func (t *Table) Save(record interface{}) error {
+	return t.SaveContext(context.Background(), record)
+}
+
+// SaveContext save the exist record with context.
+func (t *Table) SaveContext(ctx context.Context, record interface{}) error {
 	// call before hooks
-	for _, hook := range t.TableHooks.Save.Before {
-		if err := hook.(SaveHookFunc)(t, record); err != nil {
-			return err
-		}
+	if err := callRecordHooks(ctx, t.TableHooks.Save.Before, t, record); err != nil {
+		return err
 	}
 
-	err := t.save(record)
+	err := t.save(ctx, record)
 	if err != nil {
 		return err
 	}
 
 	// call after hooks
-	for _, hook := range t.TableHooks.Save.After {
-		if hookErr := hook.(SaveHookFunc)(t, record); hookErr != nil {
-			return hookErr
-		}
-	}
-
-	return nil
+	return callRecordHooks(ctx, t.TableHooks.Save.After, t, record)
 }
 
 // Update records in Table
 func (t *Table) Update(filter RowFilter, updateParts map[string]interface{}) error {
+	return t.UpdateContext(context.Background(), filter, updateParts)
+}
+
+// UpdateContext update records in Table with context.
+func (t *Table) UpdateContext(ctx context.Context, filter RowFilter, updateParts map[string]interface{}) error {
 	if len(updateParts) == 0 {
 		return nil
 	}
 
 	// call before hooks
-	for _, hook := range t.TableHooks.Update.Before {
-		if err := hook.(UpdateHookFunc)(t, filter, updateParts); err != nil {
-			return err
-		}
+	if err := callUpdateHooks(ctx, t.TableHooks.Update.Before, t, filter, updateParts); err != nil {
+		return err
 	}
 
 	updatePayload := t.RowModel()
@@ -222,53 +347,126 @@ func (t *Table) Update(filter RowFilter, updateParts map[string]interface{}) err
 		return &ErrorSQLInvalid{"invalid update parts", err}
 	}
 
-	_, err = t.update(filter, updatePayload, updateFields)
+	updateValues, err := loadValuesForUpdate(updateParts, updatePayload, updateFields)
 	if err != nil {
-		return err
+		return &ErrorSQLInvalid{"invalid update parts", err}
 	}
 
-	// call after hooks
-	for _, hook := range t.TableHooks.Update.After {
-		if hookErr := hook.(UpdateHookFunc)(t, filter, updateParts); hookErr != nil {
-			return hookErr
-		}
+	_, err = t.update(ctx, filter, updateFields, updateValues)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	// call after hooks
+	return callUpdateHooks(ctx, t.TableHooks.Update.After, t, filter, updateParts)
 }
 
-// Delete records in Table
+// Delete records in Table, the records are soft deleted when the table has `soft_delete` column,
+// see Table#HardDelete() and Table#Restore().
 func (t *Table) Delete(filter RowFilter) error {
+	return t.DeleteContext(context.Background(), filter)
+}
+
+// DeleteContext delete records in Table with context.
+func (t *Table) DeleteContext(ctx context.Context, filter RowFilter) error {
+	if t.getSchema().SoftDeleteCol() == "" {
+		return t.HardDeleteContext(ctx, filter)
+	}
+
 	// call before hooks
-	for _, hook := range t.TableHooks.Delete.Before {
-		if err := hook.(DeleteHookFunc)(t, filter); err != nil {
-			return err
-		}
+	if err := callDeleteHooks(ctx, t.TableHooks.Delete.Before, t, filter); err != nil {
+		return err
 	}
 
-	if _, err := t.deleteRows(filter); err != nil {
+	if err := t.softDeleteRows(ctx, filter); err != nil {
 		return err
 	}
 
 	// call after hooks
-	for _, hook := range t.TableHooks.Delete.After {
-		if err := hook.(DeleteHookFunc)(t, filter); err != nil {
-			return err
-		}
+	return callDeleteHooks(ctx, t.TableHooks.Delete.After, t, filter)
+}
+
+// HardDelete delete records physically in Table, including the soft deleted ones.
+func (t *Table) HardDelete(filter RowFilter) error {
+	return t.HardDeleteContext(context.Background(), filter)
+}
+
+// HardDeleteContext delete records physically in Table with context.
+func (t *Table) HardDeleteContext(ctx context.Context, filter RowFilter) error {
+	// call before hooks
+	if err := callDeleteHooks(ctx, t.TableHooks.Delete.Before, t, filter); err != nil {
+		return err
 	}
 
-	return nil
+	if _, err := t.deleteRows(ctx, filter); err != nil {
+		return err
+	}
+
+	// call after hooks
+	return callDeleteHooks(ctx, t.TableHooks.Delete.After, t, filter)
 }
 
 // List Records from Table
 func (t *Table) List(filter RowFilter, options ListOptions) ([]interface{}, error) {
-	records := make([]interface{}, 0)
-	query, wherePatterns, err := t.getSchema().SelectSQL(filter, options)
+	return t.ListContext(context.Background(), filter, options)
+}
+
+// ListContext list records from Table with context.
+// It resumes with keyset pagination when options.Cursor is setted, see Table#ListPage().
+// For the split table, it queries all shard tables and merges the records by the sort keys
+// when the split column values are absent or given as list in filter.
+func (t *Table) ListContext(ctx context.Context, filter RowFilter, options ListOptions) ([]interface{}, error) {
+	if options.Cursor != "" {
+		records, _, err := t.ListPageContext(ctx, filter, options)
+		return records, err
+	}
+
+	return t.list(ctx, filter, options)
+}
+
+// list records from the target table or the shard tables.
+func (t *Table) list(ctx context.Context, filter RowFilter, options ListOptions) ([]interface{}, error) {
+	relations, err := t.preloadRelations(options)
 	if err != nil {
+		return make([]interface{}, 0), err
+	}
+	if len(relations) > 0 && !options.AllColumns && len(options.Columns) > 0 {
+		// 预加载需要关联列的值
+		for _, r := range relations {
+			options.Columns = appendMissing(options.Columns, []string{r.LocalCol})
+		}
+	}
+
+	records, err := t.listTargets(ctx, filter, options)
+	if err != nil || len(relations) == 0 {
 		return records, err
 	}
 
-	rows, queryErr := t.queryWhenExist(query.String(), wherePatterns)
+	return records, t.preload(ctx, records, relations)
+}
+
+// listTargets list records from the target table or the shard tables without preloading.
+func (t *Table) listTargets(ctx context.Context, filter RowFilter, options ListOptions) ([]interface{}, error) {
+	tables, fanOut, err := t.queryTargets(ctx, filter)
+	if err != nil {
+		return make([]interface{}, 0), err
+	}
+	if fanOut {
+		return t.listShards(ctx, tables, filter, options)
+	}
+
+	query, wherePatterns, err := t.getSchema().selectSQL(tables[0], filter, options)
+	if err != nil {
+		return make([]interface{}, 0), err
+	}
+
+	return t.queryRecords(ctx, query, wherePatterns)
+}
+
+// queryRecords query and scan records, it's empty when table not exist.
+func (t *Table) queryRecords(ctx context.Context, query Query, wherePatterns map[string]interface{}) ([]interface{}, error) {
+	records := make([]interface{}, 0)
+	rows, queryErr := t.queryWhenExist(ctx, query.String(), wherePatterns)
 	if queryErr != nil {
 		return records, fmt.Errorf("query failed :%w\nsql: %s\nwherePatterns: %v", queryErr, &query, wherePatterns)
 	}
@@ -280,7 +478,7 @@ func (t *Table) List(filter RowFilter, options ListOptions) ([]interface{}, erro
 	defer rows.Close()
 
 	for rows.Next() {
-		record, err := t.scanRow(rows)
+		record, err := t.scanRow(rows.Rows)
 		if err != nil {
 			fmt.Printf("sqlx scan error: %s\n%s\n", err.Error(), &query)
 			return records, err
@@ -294,17 +492,25 @@ func (t *Table) List(filter RowFilter, options ListOptions) ([]interface{}, erro
 	return records, nil
 }
 
-// GetFirst Record from Table by filter
+// Get first record from Table by filter
 func (t *Table) Get(filter RowFilter, record interface{}) error {
+	return t.GetContext(context.Background(), filter, record)
+}
+
+// GetContext get first record from Table by filter with context.
+func (t *Table) GetContext(ctx context.Context, filter RowFilter, record interface{}) error {
 	query, wherePatterns, err := t.getSchema().SelectSQL(filter, ListOptions{AllColumns: true, Limit: 1})
 	if err != nil {
 		return err
 	}
 
-	rows, queryErr := t.queryWhenExist(query.String(), wherePatterns)
+	rows, queryErr := t.queryWhenExist(ctx, query.String(), wherePatterns)
 	if queryErr != nil {
 		return fmt.Errorf("query failed :%w\nsql: %s\nwherePatterns: %v", queryErr, &query, wherePatterns)
 	}
+	if rows == nil {
+		return sql.ErrNoRows
+	}
 
 	// 释放db连接
 	defer rows.Close()
@@ -318,3 +524,80 @@ func (t *Table) Get(filter RowFilter, record interface{}) error {
 
 	return rows.StructScan(record)
 }
+
+// Count records in Table by filter.
+func (t *Table) Count(filter RowFilter) (int64, error) {
+	return t.CountContext(context.Background(), filter)
+}
+
+// CountContext count records in Table by filter with context, it returns 0 when table not exist.
+// For the split table, it sums the counts of shard tables when the split column values are absent or given as list.
+func (t *Table) CountContext(ctx context.Context, filter RowFilter) (int64, error) {
+	tables, fanOut, err := t.queryTargets(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	if fanOut {
+		return t.countShards(ctx, tables, filter)
+	}
+
+	return t.countTarget(ctx, tables[0], filter)
+}
+
+// countTarget count records in the target table.
+func (t *Table) countTarget(ctx context.Context, targetTable string, filter RowFilter) (int64, error) {
+	query, wherePatterns, err := t.getSchema().selectSQL(targetTable, filter, ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	query.Columns = []string{"COUNT(*)"}
+
+	rows, queryErr := t.queryWhenExist(ctx, query.String(), wherePatterns)
+	if queryErr != nil {
+		return 0, fmt.Errorf("query failed :%w\nsql: %s\nwherePatterns: %v", queryErr, &query, wherePatterns)
+	}
+	if rows == nil {
+		return 0, nil
+	}
+
+	// 释放db连接
+	defer rows.Close()
+
+	var count int64
+	if rows.Next() {
+		if err := rows.Scan(&count); err != nil {
+			return 0, err
+		}
+	}
+
+	return count, rows.Err()
+}
+
+// Exists check whether any record matched the filter in Table.
+func (t *Table) Exists(filter RowFilter) (bool, error) {
+	return t.ExistsContext(context.Background(), filter)
+}
+
+// ExistsContext check whether any record matched the filter in Table with context,
+// it returns false when table not exist.
+func (t *Table) ExistsContext(ctx context.Context, filter RowFilter) (bool, error) {
+	query, wherePatterns, err := t.getSchema().SelectSQL(filter, ListOptions{Limit: 1})
+	if err != nil {
+		return false, err
+	}
+	query.Columns = []string{"1"}
+
+	rows, queryErr := t.queryWhenExist(ctx, query.String(), wherePatterns)
+	if queryErr != nil {
+		return false, fmt.Errorf("query failed :%w\nsql: %s\nwherePatterns: %v", queryErr, &query, wherePatterns)
+	}
+	if rows == nil {
+		return false, nil
+	}
+
+	// 释放db连接
+	defer rows.Close()
+
+	exist := rows.Next()
+	return exist, rows.Err()
+}
@@ -0,0 +1,278 @@
+package sqlm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// aggregate functions.
+const (
+	AggCount = "COUNT" // AggCount count rows or not null values of column.
+	AggSum   = "SUM"   // AggSum sum of column values.
+	AggAvg   = "AVG"   // AggAvg average of column values.
+	AggMin   = "MIN"   // AggMin minimum of column values.
+	AggMax   = "MAX"   // AggMax maximum of column values.
+)
+
+// aliasRegex limit the alias name of aggregate result.
+var aliasRegex = regexp.MustCompile(`^\w+$`)
+
+// Agg aggregate expression, such as: `COUNT(*)`, `SUM(score)`.
+type Agg struct {
+	Func  string // one of AggCount, AggSum, AggAvg, AggMin, AggMax.
+	Col   string // column to aggregate, it can be empty for AggCount to count all rows.
+	Alias string // name in result row, default is lower case func name and col joined by `_`, like: count, sum_score.
+}
+
+// Count return aggregate expression counting rows.
+func Count() Agg { return Agg{Func: AggCount} }
+
+// Sum return aggregate expression summing col values.
+func Sum(col string) Agg { return Agg{Func: AggSum, Col: col} }
+
+// Avg return aggregate expression averaging col values.
+func Avg(col string) Agg { return Agg{Func: AggAvg, Col: col} }
+
+// Min return aggregate expression for minimum of col values.
+func Min(col string) Agg { return Agg{Func: AggMin, Col: col} }
+
+// Max return aggregate expression for maximum of col values.
+func Max(col string) Agg { return Agg{Func: AggMax, Col: col} }
+
+// Name return the name in result row.
+func (a Agg) Name() string {
+	if a.Alias != "" {
+		return a.Alias
+	}
+	if a.Col == "" || a.Col == "*" {
+		return strings.ToLower(a.Func)
+	}
+
+	return strings.ToLower(a.Func) + "_" + a.Col
+}
+
+// AggregateOptions for Table#Aggregate()
+type AggregateOptions struct {
+	GroupBy    []string
+	Aggregates []Agg
+	Having     RowFilter   // filter on the aggregate results, the Col of filters is group column or aggregate name.
+	OrderBy    []OrderTerm // the Col of terms is group column or aggregate name.
+	Limit      int32
+}
+
+// AggregateRow one aggregate result row, keyed by group columns and aggregate names.
+type AggregateRow map[string]interface{}
+
+// Int64 return the value as int64.
+func (r AggregateRow) Int64(name string) (int64, error) {
+	switch v := r[name].(type) {
+	case nil:
+		return 0, nil
+	case int64:
+		return v, nil
+	case float64:
+		return int64(v), nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		return int64(f), err
+	default:
+		return 0, fmt.Errorf("can not convert %s value to int64: [%T] %v", name, v, v)
+	}
+}
+
+// Float64 return the value as float64.
+func (r AggregateRow) Float64(name string) (float64, error) {
+	switch v := r[name].(type) {
+	case nil:
+		return 0, nil
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("can not convert %s value to float64: [%T] %v", name, v, v)
+	}
+}
+
+// String return the value as string.
+func (r AggregateRow) String(name string) string {
+	v := r[name]
+	if v == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", v)
+}
+
+// Aggregate records in Table, records are grouped by options.GroupBy.
+func (t *Table) Aggregate(filter RowFilter, options AggregateOptions) ([]AggregateRow, error) {
+	return t.AggregateContext(context.Background(), filter, options)
+}
+
+// AggregateContext aggregate records in Table with context.
+func (t *Table) AggregateContext(ctx context.Context, filter RowFilter, options AggregateOptions) ([]AggregateRow, error) {
+	ret := make([]AggregateRow, 0)
+	query, patterns, err := t.getSchema().AggregateSQL(filter, options)
+	if err != nil {
+		return ret, err
+	}
+
+	rows, queryErr := t.queryWhenExist(ctx, query.String(), patterns)
+	if queryErr != nil {
+		return ret, fmt.Errorf("query failed :%w\nsql: %s\nwherePatterns: %v", queryErr, &query, patterns)
+	}
+	if rows == nil {
+		return ret, nil
+	}
+
+	// 释放db连接
+	defer rows.Close()
+
+	for rows.Next() {
+		row := make(map[string]interface{})
+		if err := rows.MapScan(row); err != nil {
+			return ret, err
+		}
+		for k, v := range row {
+			if bs, ok := v.([]byte); ok {
+				row[k] = string(bs)
+			}
+		}
+		ret = append(ret, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// AggregateSQL return sql statement for aggregate quering.
+func (t *TableSchema) AggregateSQL(rf RowFilter, options AggregateOptions) (Query, map[string]interface{}, error) {
+	var query Query
+	if len(options.Aggregates) == 0 && len(options.GroupBy) == 0 {
+		return query, nil, &ErrorSQLInvalid{Message: "aggregate requires group columns or aggregates"}
+	}
+
+	names := make(map[string]string) // result name => expression
+	for _, c := range options.GroupBy {
+		if !t.hasCol(c) {
+			return query, nil, &ErrorSQLInvalid{Message: fmt.Sprintf("unknown group column: %s", c)}
+		}
+		names[c] = t.quote(c)
+	}
+
+	columns := append([]string{}, options.GroupBy...)
+	for _, a := range options.Aggregates {
+		expr, err := t.aggregateExpr(a)
+		if err != nil {
+			return query, nil, err
+		}
+		if _, dup := names[a.Name()]; dup {
+			return query, nil, &ErrorSQLInvalid{Message: fmt.Sprintf("duplicated aggregate name: %s", a.Name())}
+		}
+
+		names[a.Name()] = expr
+		columns = append(columns, expr+" AS "+t.quote(a.Name()))
+	}
+
+	query, patterns, err := t.SelectSQL(rf, ListOptions{Columns: columns, Limit: options.Limit})
+	if err != nil {
+		return query, nil, err
+	}
+	query.GroupBy = t.quotes(options.GroupBy)
+
+	if options.Having != nil {
+		having, err := t.havingPattern(options.Having, names)
+		if err != nil {
+			return query, nil, err
+		}
+		if having != nil && having.Format != "" {
+			ret := &SQLWhere{Patterns: patterns}
+			if query.Having, err = mergeWhere(ret, having); err != nil {
+				return query, nil, err
+			}
+			patterns = ret.Patterns
+		}
+	}
+
+	for _, o := range options.OrderBy {
+		if _, ok := names[o.Col]; !ok {
+			return query, nil, &ErrorSQLInvalid{Message: fmt.Sprintf("unknown order column: %s", o.Col)}
+		}
+		o.Col = t.quote(o.Col)
+		query.OrderBy = append(query.OrderBy, o)
+	}
+
+	return query, patterns, nil
+}
+
+// aggregateExpr return the validated aggregate expression.
+func (t *TableSchema) aggregateExpr(a Agg) (string, error) {
+	switch a.Func {
+	case AggCount, AggSum, AggAvg, AggMin, AggMax:
+	default:
+		return "", &ErrorSQLInvalid{Message: fmt.Sprintf("not supported aggregate function: %q", a.Func)}
+	}
+	if !aliasRegex.MatchString(a.Name()) {
+		return "", &ErrorSQLInvalid{Message: fmt.Sprintf("invalid aggregate name: %q", a.Name())}
+	}
+
+	if a.Col == "" || a.Col == "*" {
+		if a.Func != AggCount {
+			return "", &ErrorSQLInvalid{Message: fmt.Sprintf("aggregate function %s requires column", a.Func)}
+		}
+		return a.Func + "(*)", nil
+	}
+	if !t.hasCol(a.Col) {
+		return "", &ErrorSQLInvalid{Message: fmt.Sprintf("unknown aggregate column: %s", a.Col)}
+	}
+
+	return fmt.Sprintf("%s(%s)", a.Func, t.quote(a.Col)), nil
+}
+
+// havingPattern compose having statement, the result names are replaced by their expressions
+// because aliases are not supported in having clause by some databases, such as postgresql.
+func (t *TableSchema) havingPattern(having RowFilter, names map[string]string) (*SQLWhere, error) {
+	where, err := t.wherePattern(having)
+	if err != nil {
+		return nil, fmt.Errorf("having statement composed failed: %w", err)
+	}
+	if where == nil || where.Format == "" {
+		return where, nil
+	}
+	if where.Join != nil {
+		return nil, &ErrorSQLInvalid{Message: "having中的不允许存在联合条件"}
+	}
+
+	// replace in one pass, so the column in expression is not replaced again when it's also a result name.
+	keys := make([]string, 0, len(names))
+	for name := range names {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, name := range keys {
+		if quoted := t.quote(name); quoted != name {
+			pairs = append(pairs, quoted, names[name])
+		}
+	}
+	where.Format = strings.NewReplacer(pairs...).Replace(where.Format)
+
+	return where, nil
+}
@@ -0,0 +1,157 @@
+package sqlm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTableSchema_AggregateSQL(t *testing.T) {
+	schema := &TableSchema{Name: "test_agg", Driver: DriverSQLite3}
+	schema.Columns = []*ColSchema{{Name: "id"}, {Name: "name"}, {Name: "score"}}
+
+	tests := []struct {
+		name         string
+		filter       RowFilter
+		options      AggregateOptions
+		want         string
+		wantPatterns map[string]interface{}
+		wantErr      bool
+	}{
+		{
+			"count all",
+			nil,
+			AggregateOptions{Aggregates: []Agg{Count()}},
+			`select  COUNT(*) AS "count" from "test_agg"`,
+			nil,
+			false,
+		},
+		{
+			"group with having and order",
+			CompareFilter{Col: "id", Op: OpGt, Value: 1},
+			AggregateOptions{
+				GroupBy:    []string{"score"},
+				Aggregates: []Agg{Count(), Sum("id"), {Func: AggMax, Col: "name", Alias: "last"}},
+				Having:     CompareFilter{Col: "count", Op: OpGt, Value: 1},
+				OrderBy:    []OrderTerm{{Col: "sum_id", Desc: true}, {Col: "score"}},
+				Limit:      10,
+			},
+			`select  "score",COUNT(*) AS "count",SUM("id") AS "sum_id",MAX("name") AS "last" from "test_agg"` +
+				` where "id" > :id GROUP BY "score" HAVING COUNT(*) > :count ORDER BY "sum_id" DESC, "score" LIMIT 10`,
+			map[string]interface{}{"id": 1, "count": 1},
+			false,
+		},
+		{
+			"having renames conflicted parameter",
+			SelectorFilter{"score": 1},
+			AggregateOptions{
+				GroupBy:    []string{"name"},
+				Aggregates: []Agg{{Func: AggMin, Col: "score", Alias: "score_min"}},
+				Having:     CompareFilter{Col: "score", Op: OpGe, Value: 0},
+			},
+			`select  "name",MIN("score") AS "score_min" from "test_agg" where "score"=:score GROUP BY "name" HAVING "score" >= :score_1`,
+			map[string]interface{}{"score": 1, "score_1": 0},
+			false,
+		},
+		{
+			"having with alias same as column in other expression",
+			nil,
+			AggregateOptions{
+				GroupBy:    []string{"name"},
+				Aggregates: []Agg{{Func: AggMax, Col: "score", Alias: "id"}, {Func: AggSum, Col: "id", Alias: "total"}},
+				Having:     RowFilterAnd{CompareFilter{Col: "total", Op: OpGt, Value: 1}, CompareFilter{Col: "id", Op: OpLt, Value: 9}},
+			},
+			`select  "name",MAX("score") AS "id",SUM("id") AS "total" from "test_agg" GROUP BY "name"` +
+				` HAVING SUM("id") > :total AND (MAX("score") < :id)`,
+			map[string]interface{}{"total": 1, "id": 9},
+			false,
+		},
+		{"nothing to aggregate", nil, AggregateOptions{}, "", nil, true},
+		{"unknown group column", nil, AggregateOptions{GroupBy: []string{"x"}}, "", nil, true},
+		{"unknown aggregate column", nil, AggregateOptions{Aggregates: []Agg{Sum("x")}}, "", nil, true},
+		{"sum without column", nil, AggregateOptions{Aggregates: []Agg{{Func: AggSum}}}, "", nil, true},
+		{"unsupported function", nil, AggregateOptions{Aggregates: []Agg{{Func: "STDDEV", Col: "score"}}}, "", nil, true},
+		{"invalid alias", nil, AggregateOptions{Aggregates: []Agg{{Func: AggCount, Alias: "a b"}}}, "", nil, true},
+		{"duplicated name", nil, AggregateOptions{GroupBy: []string{"score"}, Aggregates: []Agg{{Func: AggCount, Alias: "score"}}}, "", nil, true},
+		{"unknown order column", nil, AggregateOptions{Aggregates: []Agg{Count()}, OrderBy: []OrderTerm{{Col: "name"}}}, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, patterns, err := schema.AggregateSQL(tt.filter, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TableSchema.AggregateSQL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var errInvalid *ErrorSQLInvalid
+				if !errors.As(err, &errInvalid) {
+					t.Errorf("TableSchema.AggregateSQL() error = %v, want ErrorSQLInvalid", err)
+				}
+				return
+			}
+			if got := query.String(); got != tt.want {
+				t.Errorf("TableSchema.AggregateSQL() = %v, want %v", got, tt.want)
+			}
+			if len(patterns) != 0 || len(tt.wantPatterns) != 0 {
+				if !reflect.DeepEqual(patterns, tt.wantPatterns) {
+					t.Errorf("TableSchema.AggregateSQL() patterns = %v, want %v", patterns, tt.wantPatterns)
+				}
+			}
+		})
+	}
+}
+
+func TestTable_Aggregate(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_aggregate", &testSimpleRecord{})
+	var records []interface{}
+	for i, score := range []int32{3, 1, 2, 3, 1, 3} {
+		records = append(records, &testSimpleRecord{ID: int64(i + 1), Name: string(rune('a' + i)), Score: score})
+	}
+	if _, err := table.Inserts(records); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := table.Aggregate(CompareFilter{Col: "id", Op: OpLe, Value: 6}, AggregateOptions{
+		GroupBy:    []string{"score"},
+		Aggregates: []Agg{Count(), Sum("id"), Avg("id"), Min("name"), Max("name")},
+		Having:     CompareFilter{Col: "count", Op: OpGt, Value: 1},
+		OrderBy:    []OrderTerm{{Col: "count", Desc: true}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Table.Aggregate() got %d rows, want 2: %v", len(rows), rows)
+	}
+
+	want := []struct {
+		score, count, sum int64
+		avg               float64
+		min, max          string
+	}{
+		{3, 3, 11, 11.0 / 3, "a", "f"},
+		{1, 2, 7, 3.5, "b", "e"},
+	}
+	for i, w := range want {
+		row := rows[i]
+		score, _ := row.Int64("score")
+		count, _ := row.Int64("count")
+		sum, _ := row.Int64("sum_id")
+		avg, err := row.Float64("avg_id")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if score != w.score || count != w.count || sum != w.sum || avg != w.avg ||
+			row.String("min_name") != w.min || row.String("max_name") != w.max {
+			t.Errorf("Table.Aggregate() row %d = %v, want %+v", i, row, w)
+		}
+	}
+
+	t.Run("table not exist", func(t *testing.T) {
+		other := &Table{Database: table.Database, TableName: "test_aggregate_not_exist"}
+		other.SetRowModel(func() interface{} { return &testSimpleRecord{} })
+		rows, err := other.Aggregate(nil, AggregateOptions{Aggregates: []Agg{Count()}})
+		if err != nil || len(rows) != 0 {
+			t.Errorf("Table.Aggregate() = %v, %v, want empty", rows, err)
+		}
+	})
+}
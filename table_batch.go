@@ -0,0 +1,180 @@
+package sqlm
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// defaultInsertBatchSize default maximum rows in one inserting statement of Table#Inserts().
+const defaultInsertBatchSize = 500
+
+// insertBatch records inserting into the same target table.
+type insertBatch struct {
+	targetTable string
+	cols        []string
+	indexes     []int // indexes of records in the inserting list.
+	values      []map[string]interface{}
+	sizes       []int // estimated sizes of rows in bytes.
+}
+
+// groupInsertBatches group records by their target tables, the order of records is kept in each group.
+func (t *Table) groupInsertBatches(records []interface{}) ([]*insertBatch, error) {
+	cols := t.getSchema().InsertCols()
+
+	var batches []*insertBatch
+	batchOfTable := make(map[string]*insertBatch)
+	for i, r := range records {
+		targetTable, err := t.getSchema().TargetName(r)
+		if err != nil {
+			return nil, err
+		}
+		values, err := recordValues(r, cols)
+		if err != nil {
+			return nil, err
+		}
+
+		b, ok := batchOfTable[targetTable]
+		if !ok {
+			b = &insertBatch{targetTable: targetTable, cols: cols}
+			batchOfTable[targetTable] = b
+			batches = append(batches, b)
+		}
+		b.indexes = append(b.indexes, i)
+		b.values = append(b.values, values)
+		b.sizes = append(b.sizes, rowSize(values))
+	}
+
+	return batches, nil
+}
+
+// splitInsertBatch split batch into chunks limited by the batch size of table,
+// the bound variables limit and packet size limit of dialect.
+func (t *Table) splitInsertBatch(d BatchDialect, b *insertBatch) []*insertBatch {
+	maxRows := t.InsertBatchSize
+	if maxRows <= 0 {
+		maxRows = defaultInsertBatchSize
+	}
+	if maxVars := d.MaxBindVars(); maxVars > 0 && maxVars/len(b.cols) < maxRows {
+		maxRows = maxVars / len(b.cols)
+	}
+	if maxRows < 1 {
+		maxRows = 1
+	}
+	maxSize := d.MaxPacketSize()
+	baseSize := len(t.getSchema().insertSQL(b.targetTable))
+
+	var chunks []*insertBatch
+	var chunk *insertBatch
+	var chunkSize int
+	for i := range b.values {
+		full := chunk != nil && (len(chunk.values) >= maxRows || (maxSize > 0 && chunkSize+b.sizes[i] > maxSize))
+		if chunk == nil || full {
+			chunk = &insertBatch{targetTable: b.targetTable, cols: b.cols}
+			chunks = append(chunks, chunk)
+			chunkSize = baseSize
+		}
+
+		chunk.indexes = append(chunk.indexes, b.indexes[i])
+		chunk.values = append(chunk.values, b.values[i])
+		chunk.sizes = append(chunk.sizes, b.sizes[i])
+		chunkSize += b.sizes[i]
+	}
+
+	return chunks
+}
+
+// insertChunk insert the rows of chunk in one statement, return their ids in order,
+// the ids are zero when the table has no auto increment column or they can not be inferred by dialect.
+func (t *Table) insertChunk(ctx context.Context, d BatchDialect, chunk *insertBatch) ([]int64, error) {
+	query := t.getSchema().BatchInsertSQL(chunk.targetTable, len(chunk.values))
+	args := make(map[string]interface{}, len(chunk.values)*len(chunk.cols))
+	for i, values := range chunk.values {
+		for _, k := range chunk.cols {
+			args[batchPatternName(k, i)] = values[k]
+		}
+	}
+
+	insertQuery := &targetQuery{chunk.targetTable, query, strings.Contains(query, " RETURNING ")}
+	if insertQuery.returning {
+		ids, err := t.queryIDsWithAutoCreate(ctx, insertQuery, args)
+		if err == nil && len(ids) != len(chunk.values) {
+			return nil, fmt.Errorf("returned ids count %d not matched with rows count %d", len(ids), len(chunk.values))
+		}
+		return ids, err
+	}
+
+	ret, err := t.execWithAutoCreate(ctx, insertQuery, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.getSchema().AutoIncrementCol() == "" {
+		return make([]int64, len(chunk.values)), nil
+	}
+	lastInsertID, _ := ret.LastInsertId()
+	if ids := d.BatchInsertIDs(lastInsertID, len(chunk.values)); len(ids) == len(chunk.values) {
+		return ids, nil
+	}
+
+	return make([]int64, len(chunk.values)), nil
+}
+
+// recordValues return the values of columns in record, the record is a struct or map keyed by column names.
+func recordValues(record interface{}, cols []string) (map[string]interface{}, error) {
+	if m, ok := record.(map[string]interface{}); ok {
+		return m, nil
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(record))
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("not supported record type: %T", record)
+	}
+
+	fields := reflectx.NewMapper(DBSchemaTag).FieldMap(v)
+	ret := make(map[string]interface{}, len(cols))
+	for _, k := range cols {
+		f, ok := fields[k]
+		if !ok {
+			return nil, fmt.Errorf("column %s not found in record: %T", k, record)
+		}
+		ret[k] = f.Interface()
+	}
+
+	return ret, nil
+}
+
+// rowSize estimate the size of row in inserting statement.
+func rowSize(values map[string]interface{}) int {
+	// placeholders, separators and brackets.
+	size := 3
+	for _, v := range values {
+		size += 2 + valueSize(v)
+	}
+
+	return size
+}
+
+func valueSize(v interface{}) int {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return 0
+	}
+	if valuer, ok := v.(driver.Valuer); ok {
+		if dv, err := valuer.Value(); err == nil {
+			v = dv
+		}
+	}
+
+	switch e := v.(type) {
+	case string:
+		return len(e)
+	case []byte:
+		return len(e)
+	default:
+		return 8
+	}
+}
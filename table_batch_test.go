@@ -0,0 +1,234 @@
+package sqlm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTable_splitInsertBatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		driver     string
+		batchSize  int
+		count      int
+		nameSize   int
+		wantChunks []int
+	}{
+		{"by batch size", DriverSQLite3, 2, 5, 1, []int{2, 2, 1}},
+		{"by default batch size", DriverMysql, 0, 600, 1, []int{500, 100}},
+		{"by bound variables limit", DriverSQLite3, 1000, 1200, 1, []int{499, 499, 202}},
+		{"by packet size limit", DriverMysql, 0, 5, 2 << 20, []int{1, 1, 1, 1, 1}},
+		{"large rows under packet size limit", DriverMysql, 0, 5, 1 << 20, []int{3, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &Table{Database: &Database{Driver: tt.driver}, TableName: "test", InsertBatchSize: tt.batchSize}
+			table.SetRowModel(func() interface{} { return &testSimpleRecord{} })
+			dialect, err := table.dialect()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var records []interface{}
+			for i := 0; i < tt.count; i++ {
+				records = append(records, &testSimpleRecord{ID: int64(i + 1), Name: strings.Repeat("a", tt.nameSize)})
+			}
+			batches, err := table.groupInsertBatches(records)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(batches) != 1 {
+				t.Fatalf("Table.groupInsertBatches() got %d batches, want 1", len(batches))
+			}
+
+			var gotChunks []int
+			var gotIndexes []int
+			for _, c := range table.splitInsertBatch(dialect.(BatchDialect), batches[0]) {
+				gotChunks = append(gotChunks, len(c.values))
+				gotIndexes = append(gotIndexes, c.indexes...)
+			}
+			if !reflect.DeepEqual(gotChunks, tt.wantChunks) {
+				t.Errorf("Table.splitInsertBatch() chunks = %v, want %v", gotChunks, tt.wantChunks)
+			}
+			for i, index := range gotIndexes {
+				if index != i {
+					t.Fatalf("Table.splitInsertBatch() indexes = %v, want in order", gotIndexes)
+				}
+			}
+		})
+	}
+}
+
+func TestTable_groupInsertBatches(t *testing.T) {
+	table := &Table{Database: &Database{Driver: DriverMysql}, TableName: "test"}
+	table.SetRowModel(func() interface{} { return &testRecord{} })
+
+	records := []interface{}{
+		&testRecord{ProjectID: 1, RuleID: 1},
+		&testRecord{ProjectID: 2, RuleID: 2},
+		&testRecord{ProjectID: 1, RuleID: 3},
+	}
+	batches, err := table.groupInsertBatches(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, b := range batches {
+		got = append(got, fmt.Sprintf("%s:%v", b.targetTable, b.indexes))
+	}
+	if want := []string{"test_1:[0 2]", "test_2:[1]"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Table.groupInsertBatches() = %v, want %v", got, want)
+	}
+
+	if _, err := table.groupInsertBatches([]interface{}{1}); err == nil {
+		t.Error("Table.groupInsertBatches() should fail for not supported record type")
+	}
+}
+
+func TestTable_batchInserts(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize int
+		count     int
+	}{
+		{"one by one", 1, 5},
+		{"chunked by batch size", 2, 5},
+		{"chunked by bound variables limit", 1000, 700},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newTestSQLiteTable(t, "test_batch_inserts", &testSimpleRecord{})
+			table.InsertBatchSize = tt.batchSize
+
+			var hookCalls int
+			table.TableHooks.Inserts.After = []interface{}{
+				InsertsHookFunc(func(_ *Table, got []interface{}) error {
+					hookCalls++
+					if len(got) != tt.count {
+						t.Errorf("Inserts hook got %d records, want %d", len(got), tt.count)
+					}
+					return nil
+				}),
+			}
+
+			var records []interface{}
+			var wantIDs []int64
+			for i := 0; i < tt.count; i++ {
+				records = append(records, &testSimpleRecord{ID: int64(i + 1), Name: fmt.Sprintf("n%d", i), Score: int32(i % 3)})
+				wantIDs = append(wantIDs, int64(i+1))
+			}
+
+			ids, err := table.Inserts(records)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ids, wantIDs) {
+				t.Errorf("Table.Inserts() ids = %v, want %v", ids, wantIDs)
+			}
+			if hookCalls != 1 {
+				t.Errorf("Inserts hook called %d times, want 1", hookCalls)
+			}
+
+			count, err := table.Count(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != int64(tt.count) {
+				t.Errorf("Table.Count() = %v, want %v", count, tt.count)
+			}
+
+			// 批量中存在冲突时整条语句失败
+			if _, err := table.Inserts(records[:1]); err == nil {
+				t.Error("Table.Inserts() should fail with duplicated records")
+			}
+		})
+	}
+}
+
+func TestTable_batchInserts_autoCreate(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_batch_shard", &testShardRecord{})
+
+	// 每个分表插入时自动创建
+	records := []interface{}{
+		&testShardRecord{ID: 1, Group: "a"},
+		&testShardRecord{ID: 2, Group: "b"},
+		&testShardRecord{ID: 3, Group: "a"},
+	}
+	ids, err := table.Inserts(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// auto increment ids are generated in each shard.
+	if want := []int64{1, 1, 2}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Table.Inserts() ids = %v, want %v", ids, want)
+	}
+	for group, want := range map[string]int64{"a": 2, "b": 1} {
+		if count, err := table.Count(SelectorFilter{"grp": group}); err != nil || count != want {
+			t.Errorf("Table.Count() of group %s = %v, %v, want %v", group, count, err, want)
+		}
+	}
+}
+
+func TestTable_batchInserts_afterCreate(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_batch_created", &testSimpleRecord{})
+
+	records := []interface{}{
+		&testSimpleRecord{Name: "a", Score: 1},
+		&testSimpleRecord{Name: "b", Score: 2},
+	}
+	ids, err := table.Inserts(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1, 2}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Table.Inserts() ids = %v, want %v", ids, want)
+	}
+	if count, err := table.Count(nil); err != nil || count != 2 {
+		t.Errorf("Table.Count() = %v, %v, want 2", count, err)
+	}
+}
+
+func TestTable_batchInserts_withoutAutoIncrement(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_batch_no_auto", &testSoftRecord{})
+
+	records := []interface{}{&testSoftRecord{ID: 5, Name: "a"}, &testSoftRecord{ID: 9, Name: "b"}}
+	ids, err := table.Inserts(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ids are not inferred without auto increment column.
+	if want := []int64{0, 0}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Table.Inserts() ids = %v, want %v", ids, want)
+	}
+}
+
+func TestMySQLDialect_BatchInsertIDs(t *testing.T) {
+	if got := new(MySQLDialect).BatchInsertIDs(10, 3); got != nil {
+		t.Errorf("MySQLDialect.BatchInsertIDs() = %v, want nil", got)
+	}
+	d := &MySQLDialect{ConsecutiveInsertIDs: true}
+	if got, want := d.BatchInsertIDs(10, 3), []int64{10, 11, 12}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MySQLDialect.BatchInsertIDs() = %v, want %v", got, want)
+	}
+}
+
+func TestTable_batchInserts_partial(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_batch_partial", &testSimpleRecord{})
+	table.InsertBatchSize = 2
+
+	// the second chunk conflicts with the first one.
+	records := []interface{}{
+		&testSimpleRecord{Name: "a"}, &testSimpleRecord{Name: "b"},
+		&testSimpleRecord{Name: "c"}, &testSimpleRecord{Name: "a"},
+	}
+	ids, err := table.Inserts(records)
+	if err == nil {
+		t.Fatal("Table.Inserts() should fail with duplicated records")
+	}
+	if want := []int64{1, 2}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Table.Inserts() ids = %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,610 @@
+package sqlm
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// defaultFanOutConcurrency default maximum shards queried at the same time in fan-out querying.
+const defaultFanOutConcurrency = 4
+
+// queryTargets return the target tables for querying by filter. It's the only one computed by the split
+// column values in filter, or the shard tables for fan-out when the values are absent or given as list.
+func (t *Table) queryTargets(ctx context.Context, filter RowFilter) (tables []string, fanOut bool, err error) {
+	schema := t.getSchema()
+	if len(schema.splitByColumns) == 0 {
+		return []string{t.TableName}, false, nil
+	}
+
+	if filter != nil {
+		targetTable, err := schema.TargetName(filter)
+		var errMissing *ErrorSplitColMissing
+		if err != nil && !errors.As(err, &errMissing) {
+			return nil, false, err
+		}
+		if err == nil && targetTable != "" {
+			return []string{targetTable}, false, nil
+		}
+	}
+
+	shards, err := t.ShardTablesContext(ctx)
+	if err != nil {
+		return nil, true, err
+	}
+
+	candidates := t.splitCandidates(filter)
+	for _, shard := range shards {
+		if shard == t.TableName {
+			// 分表的记录不会写入原表
+			continue
+		}
+		if candidates == nil || candidates[shard] {
+			tables = append(tables, shard)
+		}
+	}
+
+	return tables, true, nil
+}
+
+// splitCandidates return the shard tables for the split column values given as list by ColListFilter
+// or InFilter, nil means all shards are candidates. Only the table split by one column is supported.
+func (t *Table) splitCandidates(filter RowFilter) map[string]bool {
+	splitCols := t.getSchema().splitByColumns
+	if len(splitCols) != 1 {
+		return nil
+	}
+
+	var values []interface{}
+	switch f := filter.(type) {
+	case ColListFilter:
+		if f.Col == splitCols[0] {
+			values = f.Values
+		}
+	case InFilter:
+		if f.Col == splitCols[0] {
+			values = f.Values
+		}
+	case RowFilterAnd:
+		for _, child := range f {
+			if ret := t.splitCandidates(child); ret != nil {
+				return ret
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	ret := make(map[string]bool, len(values))
+	for _, v := range values {
+		ret[fmt.Sprintf("%s_%v", t.TableName, v)] = true
+	}
+
+	return ret
+}
+
+// fanOut run fn for each shard table concurrently, limited by Table#FanOutConcurrency.
+// The ctx of others is canceled on the first error, which is returned after all finished.
+func (t *Table) fanOut(ctx context.Context, tables []string, fn func(ctx context.Context, i int, table string) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var firstErr error
+	var errOnce sync.Once
+	setErr := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	sem := make(chan struct{}, t.fanOutConcurrency())
+	var wg sync.WaitGroup
+	for i, table := range tables {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+		}
+		if err := ctx.Err(); err != nil {
+			setErr(err)
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, table string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := fn(ctx, i, table); err != nil {
+				setErr(err)
+			}
+		}(i, table)
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// fanOutConcurrency return the maximum shards queried at the same time.
+func (t *Table) fanOutConcurrency() int {
+	// the transaction can not be used concurrently.
+	if t.tx != nil {
+		return 1
+	}
+	if t.FanOutConcurrency <= 0 {
+		return defaultFanOutConcurrency
+	}
+
+	return t.FanOutConcurrency
+}
+
+// shardListOptions return the options for querying each shard: the global offset is applied after merging,
+// and the order columns are selected for merging. The null values are sorted explicitly as the smallest
+// same as compareRecords, because the default order of them varies, such as the largest in postgresql.
+// The sort keys are checked for merging when there are more than one shards.
+func (t *Table) shardListOptions(options ListOptions, shards int) (ListOptions, error) {
+	ret := options
+	ret.Offset = 0
+	if options.Limit > 0 {
+		ret.Limit = options.Limit + options.Offset
+	}
+	terms := options.orderTerms()
+	if !ret.AllColumns && len(ret.Columns) > 0 {
+		ret.Columns = appendMissing(ret.Columns, orderCols(terms))
+	}
+	if len(terms) == 0 {
+		return ret, nil
+	}
+	if shards > 1 {
+		if err := t.checkMergedTerms(terms); err != nil {
+			return ret, err
+		}
+	}
+
+	ret.OrderBy = make([]OrderTerm, 0, len(terms))
+	for _, o := range terms {
+		if c := t.getSchema().col(o.Col); c != nil && !c.NotNull && !c.Primary && !o.NullsFirst {
+			if o.Desc {
+				o.nullsLast = true
+			} else {
+				o.NullsFirst = true
+			}
+		}
+		ret.OrderBy = append(ret.OrderBy, o)
+	}
+
+	return ret, nil
+}
+
+// checkMergedTerms check the records of shards can be merged by the sort keys in go, strings are compared
+// by bytes, which may disagree with the collation of database, such as the case insensitive ones of mysql.
+func (t *Table) checkMergedTerms(terms []OrderTerm) error {
+	d, err := t.dialect()
+	if err != nil {
+		return err
+	}
+	if merge, ok := d.(MergeDialect); ok && merge.BinaryStringOrder() {
+		return nil
+	}
+
+	for _, o := range terms {
+		c := t.getSchema().col(o.Col)
+		if c == nil || c.goType == nil {
+			continue
+		}
+		goType := c.goType
+		for goType.Kind() == reflect.Ptr {
+			goType = goType.Elem()
+		}
+		if goColKind(goType) == colKindString {
+			return &ErrorSQLInvalid{Message: fmt.Sprintf(
+				"ordering across shards by string column %s is not supported, the collation may be not binary", o.Col)}
+		}
+	}
+
+	return nil
+}
+
+// listShards list records from the shard tables, they are merged by the sort keys of options,
+// then the global offset and limit are applied. Distinct is applied in each shard.
+func (t *Table) listShards(ctx context.Context, tables []string, filter RowFilter, options ListOptions) ([]interface{}, error) {
+	shardOptions, err := t.shardListOptions(options, len(tables))
+	if err != nil {
+		return make([]interface{}, 0), err
+	}
+	shardRecords := make([][]interface{}, len(tables))
+	err = t.fanOut(ctx, tables, func(ctx context.Context, i int, table string) error {
+		query, wherePatterns, err := t.getSchema().selectSQL(table, filter, shardOptions)
+		if err != nil {
+			return err
+		}
+
+		shardRecords[i], err = t.queryRecords(ctx, query, wherePatterns)
+		return err
+	})
+	if err != nil {
+		return make([]interface{}, 0), err
+	}
+
+	records := make([]interface{}, 0)
+	for _, rs := range shardRecords {
+		records = append(records, rs...)
+	}
+	if terms := options.orderTerms(); len(terms) > 0 {
+		mapper := reflectx.NewMapper(DBSchemaTag)
+		sort.SliceStable(records, func(i, j int) bool {
+			return compareRecords(mapper, records[i], records[j], terms) < 0
+		})
+	}
+
+	if int(options.Offset) >= len(records) {
+		return make([]interface{}, 0), nil
+	}
+	records = records[options.Offset:]
+	if options.Limit > 0 && int(options.Limit) < len(records) {
+		records = records[:options.Limit]
+	}
+
+	return records, nil
+}
+
+// countShards return the sum of records count in shard tables.
+func (t *Table) countShards(ctx context.Context, tables []string, filter RowFilter) (int64, error) {
+	counts := make([]int64, len(tables))
+	err := t.fanOut(ctx, tables, func(ctx context.Context, i int, table string) error {
+		var err error
+		counts[i], err = t.countTarget(ctx, table, filter)
+		return err
+	})
+
+	var ret int64
+	for _, c := range counts {
+		ret += c
+	}
+
+	return ret, err
+}
+
+// shardRows iterate records of the shard tables, records are merged by the sort keys when ordered,
+// otherwise the shards are iterated one by one.
+type shardRows struct {
+	table   *Table
+	terms   []OrderTerm
+	queries []func() (*queryRows, error) // query of each shard, it's opened lazily.
+	rows    []*queryRows
+	heads   []interface{} // the next record of each shard in ordered iterating, nil when exhausted.
+	current int           // the shard iterating in unordered iterating.
+	offset  int64         // records should be skipped.
+	limit   int64         // records remained, negative means no limit.
+	opened  bool
+	mapper  *reflectx.Mapper
+}
+
+// newShardRows return rows iterating the shard tables.
+func (t *Table) newShardRows(ctx context.Context, tables []string, filter RowFilter, options ListOptions) (*shardRows, error) {
+	terms := options.orderTerms()
+	if len(terms) > 0 && len(tables) > 1 {
+		if err := t.checkOrderedShards(len(tables)); err != nil {
+			return nil, err
+		}
+	}
+
+	ret := &shardRows{
+		table:  t,
+		terms:  terms,
+		rows:   make([]*queryRows, len(tables)),
+		heads:  make([]interface{}, len(tables)),
+		offset: int64(options.Offset),
+		limit:  -1,
+		mapper: reflectx.NewMapper(DBSchemaTag),
+	}
+	if options.Limit > 0 {
+		ret.limit = int64(options.Limit)
+	}
+
+	shardOptions, err := t.shardListOptions(options, len(tables))
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		query, wherePatterns, err := t.getSchema().selectSQL(table, filter, shardOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		ret.queries = append(ret.queries, func() (*queryRows, error) {
+			rows, err := t.queryWhenExist(ctx, query.String(), wherePatterns)
+			if err != nil {
+				return nil, fmt.Errorf("query failed :%w\nsql: %s\nwherePatterns: %v", err, &query, wherePatterns)
+			}
+			return rows, nil
+		})
+	}
+
+	return ret, nil
+}
+
+// checkOrderedShards check the shards can be iterated in order, which are queried at the same time
+// with one connection for each. The shards should not be more than Table#FanOutConcurrency and
+// the maximum open connections of database, otherwise the iterating may wait for connections forever.
+func (t *Table) checkOrderedShards(shards int) error {
+	if t.tx != nil {
+		return &ErrorSQLInvalid{Message: "ordered iterating across shards is not supported in transaction"}
+	}
+	if concurrency := t.fanOutConcurrency(); shards > concurrency {
+		return &ErrorSQLInvalid{Message: fmt.Sprintf(
+			"ordered iterating across %d shards exceeds the fan-out concurrency %d, raise it or use ListPage instead", shards, concurrency)}
+	}
+
+	con, err := t.Database.Con()
+	if err != nil {
+		return err
+	}
+	if maxOpen := con.Stats().MaxOpenConnections; maxOpen > 0 && shards > maxOpen {
+		return &ErrorSQLInvalid{Message: fmt.Sprintf(
+			"ordered iterating across %d shards exceeds the maximum open connections %d of database", shards, maxOpen)}
+	}
+
+	return nil
+}
+
+// next return the next record, nil when no more records.
+func (s *shardRows) next() (interface{}, error) {
+	for ; s.offset > 0; s.offset-- {
+		record, err := s.nextRecord()
+		if record == nil || err != nil {
+			return nil, err
+		}
+	}
+	if s.limit == 0 {
+		return nil, nil
+	}
+
+	record, err := s.nextRecord()
+	if record != nil && s.limit > 0 {
+		s.limit--
+	}
+
+	return record, err
+}
+
+func (s *shardRows) nextRecord() (interface{}, error) {
+	if len(s.terms) == 0 {
+		return s.nextUnordered()
+	}
+
+	if !s.opened {
+		s.opened = true
+		for i := range s.queries {
+			if err := s.open(i); err != nil {
+				return nil, err
+			}
+			if err := s.advance(i); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	min := -1
+	for i, head := range s.heads {
+		if head != nil && (min < 0 || compareRecords(s.mapper, head, s.heads[min], s.terms) < 0) {
+			min = i
+		}
+	}
+	if min < 0 {
+		return nil, nil
+	}
+
+	record := s.heads[min]
+	return record, s.advance(min)
+}
+
+func (s *shardRows) nextUnordered() (interface{}, error) {
+	for ; s.current < len(s.queries); s.current++ {
+		if !s.opened {
+			s.opened = true
+			if err := s.open(s.current); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := s.advance(s.current); err != nil {
+			return nil, err
+		}
+		if record := s.heads[s.current]; record != nil {
+			return record, nil
+		}
+		s.opened = false
+	}
+
+	return nil, nil
+}
+
+// open query the shard, the rows are nil when the shard table not exists.
+func (s *shardRows) open(i int) (err error) {
+	s.rows[i], err = s.queries[i]()
+	return err
+}
+
+// advance scan the next record of shard into heads, the rows are closed when exhausted.
+func (s *shardRows) advance(i int) error {
+	s.heads[i] = nil
+	rows := s.rows[i]
+	if rows == nil {
+		return nil
+	}
+
+	if !rows.Next() {
+		s.rows[i] = nil
+		err := rows.Err()
+		if closeErr := rows.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}
+
+	record, err := s.table.scanRow(rows.Rows)
+	if err != nil {
+		return err
+	}
+	s.heads[i] = record
+
+	return nil
+}
+
+// close release the db connections.
+func (s *shardRows) close() error {
+	var err error
+	for i, rows := range s.rows {
+		if rows == nil {
+			continue
+		}
+		if closeErr := rows.Close(); err == nil {
+			err = closeErr
+		}
+		s.rows[i] = nil
+	}
+
+	return err
+}
+
+// compareRecords compare the column values of records by the sort keys, the null values are the smallest
+// unless NullsFirst is set, the shards are queried in the same order by Table#shardListOptions().
+func compareRecords(mapper *reflectx.Mapper, a, b interface{}, terms []OrderTerm) int {
+	fieldsA := mapper.FieldMap(reflect.ValueOf(a))
+	fieldsB := mapper.FieldMap(reflect.ValueOf(b))
+	for _, o := range terms {
+		va, vb := comparableValue(fieldsA[o.Col]), comparableValue(fieldsB[o.Col])
+
+		var ret int
+		switch {
+		case va == nil && vb == nil:
+			continue
+		case va == nil || vb == nil:
+			ret = 1
+			if va == nil {
+				ret = -1
+			}
+			if o.NullsFirst {
+				return ret
+			}
+		default:
+			ret = compareValues(va, vb)
+		}
+
+		if o.Desc {
+			ret = -ret
+		}
+		if ret != 0 {
+			return ret
+		}
+	}
+
+	return 0
+}
+
+// comparableValue return the value of field for comparing, nil for null value.
+func comparableValue(f reflect.Value) interface{} {
+	if !f.IsValid() {
+		return nil
+	}
+	if f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return nil
+		}
+		f = f.Elem()
+	}
+
+	v := f.Interface()
+	if valuer, ok := v.(driver.Valuer); ok {
+		dv, err := valuer.Value()
+		if err != nil {
+			return nil
+		}
+		return dv
+	}
+
+	return v
+}
+
+// compareValues compare the values of the same column.
+func compareValues(a, b interface{}) int {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case isIntKind(va.Kind()) && isIntKind(vb.Kind()):
+		return compareOrdered(va.Int() > vb.Int(), va.Int() < vb.Int())
+	case isUintKind(va.Kind()) && isUintKind(vb.Kind()):
+		return compareOrdered(va.Uint() > vb.Uint(), va.Uint() < vb.Uint())
+	case isNumberKind(va.Kind()) && isNumberKind(vb.Kind()):
+		x, y := numberValue(va), numberValue(vb)
+		return compareOrdered(x > y, x < y)
+	}
+
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+	case []byte:
+		if y, ok := b.([]byte); ok {
+			return bytes.Compare(x, y)
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return compareOrdered(x.After(y), x.Before(y))
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return compareOrdered(x && !y, !x && y)
+		}
+	}
+
+	return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+}
+
+// compareOrdered return 1 when greater, -1 when less, otherwise 0.
+func compareOrdered(greater, less bool) int {
+	switch {
+	case greater:
+		return 1
+	case less:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	return isIntKind(k) || isUintKind(k) || k == reflect.Float32 || k == reflect.Float64
+}
+
+func numberValue(v reflect.Value) float64 {
+	switch {
+	case isIntKind(v.Kind()):
+		return float64(v.Int())
+	case isUintKind(v.Kind()):
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
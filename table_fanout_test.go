@@ -0,0 +1,422 @@
+package sqlm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testFanOutRecord the ids are unique across shards.
+type testFanOutRecord struct {
+	ID    int64  `db:"id,type=INTEGER,primary"`
+	Group string `db:"grp,type=VARCHAR(16),not_null,split"`
+	Name  string `db:"name,type=VARCHAR(32)"`
+}
+
+func newTestFanOutTable(t *testing.T) *Table {
+	table := newTestSQLiteTable(t, "test_fanout", &testFanOutRecord{})
+	records := []interface{}{
+		&testFanOutRecord{ID: 1, Group: "a", Name: "n1"},
+		&testFanOutRecord{ID: 2, Group: "b", Name: "n2"},
+		&testFanOutRecord{ID: 3, Group: "c", Name: "n3"},
+		&testFanOutRecord{ID: 4, Group: "a", Name: "n4"},
+		&testFanOutRecord{ID: 5, Group: "b", Name: "n5"},
+		&testFanOutRecord{ID: 6, Group: "a", Name: "n6"},
+	}
+	if _, err := table.Inserts(records); err != nil {
+		t.Fatal(err)
+	}
+
+	return table
+}
+
+func testRecordIDs(records []interface{}) []int64 {
+	ids := make([]int64, 0, len(records))
+	for _, r := range records {
+		ids = append(ids, r.(*testFanOutRecord).ID)
+	}
+
+	return ids
+}
+
+func TestTable_List_fanOut(t *testing.T) {
+	table := newTestFanOutTable(t)
+
+	tests := []struct {
+		name    string
+		filter  RowFilter
+		options ListOptions
+		want    []int64
+	}{
+		{"all", nil, ListOptions{OrderByColumn: "id"}, []int64{1, 2, 3, 4, 5, 6}},
+		{"desc with limit", nil, ListOptions{OrderByColumn: "id", OrderDesc: true, Limit: 2}, []int64{6, 5}},
+		{"offset and limit", nil, ListOptions{OrderByColumn: "id", Offset: 2, Limit: 3}, []int64{3, 4, 5}},
+		{"offset beyond", nil, ListOptions{OrderByColumn: "id", Offset: 6}, []int64{}},
+		{"selected columns", nil, ListOptions{Columns: []string{"name"}, OrderByColumn: "id", Limit: 2}, []int64{1, 2}},
+		{
+			"without split column",
+			CompareFilter{Col: "id", Op: OpGe, Value: 3},
+			ListOptions{OrderByColumn: "name", OrderDesc: true},
+			[]int64{6, 5, 4, 3},
+		},
+		{
+			"split values as list",
+			ColListFilter{Col: "grp", Values: []interface{}{"b", "c", "d"}},
+			ListOptions{OrderByColumn: "id"},
+			[]int64{2, 3, 5},
+		},
+		{
+			"split values as list in and",
+			RowFilterAnd{CompareFilter{Col: "id", Op: OpGt, Value: 1}, ColListFilter{Col: "grp", Values: []interface{}{"a"}}},
+			ListOptions{OrderByColumn: "id"},
+			[]int64{4, 6},
+		},
+		{"single shard", SelectorFilter{"grp": "a"}, ListOptions{OrderByColumn: "id", OrderDesc: true}, []int64{6, 4, 1}},
+		{"compare split column", CompareFilter{Col: "grp", Op: OpGe, Value: "b"}, ListOptions{OrderByColumn: "id"}, []int64{2, 3, 5}},
+		{"like split column", LikeFilter{Key: "grp", Value: "b%"}, ListOptions{OrderByColumn: "id"}, []int64{2, 5}},
+		{"negate split column", NotFilter{SelectorFilter{"grp": "a"}}, ListOptions{OrderByColumn: "id"}, []int64{2, 3, 5}},
+		{
+			"compare before selector on split column",
+			RowFilterAnd{CompareFilter{Col: "grp", Op: OpGe, Value: "a"}, SelectorFilter{"grp": "b"}},
+			ListOptions{OrderByColumn: "id"},
+			[]int64{2, 5},
+		},
+		{
+			"selector before compare on split column",
+			RowFilterAnd{SelectorFilter{"grp": "b"}, CompareFilter{Col: "grp", Op: OpGe, Value: "a"}},
+			ListOptions{OrderByColumn: "id"},
+			[]int64{2, 5},
+		},
+		{
+			"conflicted split values",
+			RowFilterAnd{SelectorFilter{"grp": "a"}, CompareFilter{Col: "grp", Op: OpEq, Value: "b"}},
+			ListOptions{OrderByColumn: "id"},
+			[]int64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.List(tt.filter, tt.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ids := testRecordIDs(got); !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("Table.List() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestTable_Count_fanOut(t *testing.T) {
+	table := newTestFanOutTable(t)
+
+	tests := []struct {
+		name   string
+		filter RowFilter
+		want   int64
+	}{
+		{"all", nil, 6},
+		{"without split column", CompareFilter{Col: "id", Op: OpGt, Value: 2}, 4},
+		{"split values as list", ColListFilter{Col: "grp", Values: []interface{}{"a", "c"}}, 4},
+		{"split values in list", InFilter{Col: "grp", Values: []interface{}{"b", "c"}}, 3},
+		{"single shard", SelectorFilter{"grp": "b"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.Count(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Table.Count() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTable_Iterate_fanOut(t *testing.T) {
+	table := newTestFanOutTable(t)
+
+	tests := []struct {
+		name    string
+		options ListOptions
+		want    []int64
+	}{
+		{"ordered", ListOptions{OrderByColumn: "id", OrderDesc: true}, []int64{6, 5, 4, 3, 2, 1}},
+		{"ordered with offset and limit", ListOptions{OrderByColumn: "id", Offset: 1, Limit: 3}, []int64{2, 3, 4}},
+		{"unordered", ListOptions{}, []int64{1, 4, 6, 2, 5, 3}},
+		{"unordered with offset and limit", ListOptions{Offset: 2, Limit: 2}, []int64{6, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []interface{}
+			err := table.Iterate(nil, tt.options, func(record interface{}) error {
+				got = append(got, record)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ids := testRecordIDs(got); !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("Table.Iterate() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+
+	t.Run("stop early", func(t *testing.T) {
+		it, err := table.Iterator(nil, ListOptions{OrderByColumn: "id"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !it.Next() || it.Record().(*testFanOutRecord).ID != 1 {
+			t.Errorf("RowIterator.Next() = %v, want record 1", it.Record())
+		}
+		if err := it.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if it.Next() {
+			t.Errorf("RowIterator.Next() after closed should be false")
+		}
+	})
+
+	t.Run("ordered exceeding limits", func(t *testing.T) {
+		var errInvalid *ErrorSQLInvalid
+		limited := &Table{Database: table.Database, TableName: table.TableName, FanOutConcurrency: 2}
+		limited.SetRowModel(func() interface{} { return &testFanOutRecord{} })
+		if _, err := limited.Iterator(nil, ListOptions{OrderByColumn: "id"}); !errors.As(err, &errInvalid) {
+			t.Errorf("Table.Iterator() exceeding fan-out concurrency error = %v, want ErrorSQLInvalid", err)
+		}
+		if _, err := limited.Iterator(nil, ListOptions{}); err != nil {
+			t.Errorf("Table.Iterator() unordered error = %v, want nil", err)
+		}
+
+		con, err := table.Database.Con()
+		if err != nil {
+			t.Fatal(err)
+		}
+		con.SetMaxOpenConns(2)
+		defer con.SetMaxOpenConns(0)
+		if _, err := table.Iterator(nil, ListOptions{OrderByColumn: "id"}); !errors.As(err, &errInvalid) {
+			t.Errorf("Table.Iterator() exceeding max open connections error = %v, want ErrorSQLInvalid", err)
+		}
+	})
+}
+
+func TestTable_ListPage_fanOut(t *testing.T) {
+	table := newTestFanOutTable(t)
+
+	var got []interface{}
+	options := ListOptions{OrderByColumn: "id", Limit: 4}
+	for {
+		records, cursor, err := table.ListPage(nil, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, records...)
+		if cursor == "" {
+			break
+		}
+		options.Cursor = cursor
+	}
+
+	if ids, want := testRecordIDs(got), []int64{1, 2, 3, 4, 5, 6}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Table.ListPage() = %v, want %v", ids, want)
+	}
+}
+
+func Test_compareValues(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		a, b interface{}
+		want int
+	}{
+		{int64(1), int32(2), -1},
+		{int64(1<<62 + 1), int64(1 << 62), 1},
+		{uint8(3), uint64(3), 0},
+		{1.5, int64(1), 1},
+		{"a", "b", -1},
+		{[]byte("b"), []byte("a"), 1},
+		{now, now.Add(time.Second), -1},
+		{true, false, 1},
+		{false, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v-%v", tt.a, tt.b), func(t *testing.T) {
+			if got := compareValues(tt.a, tt.b); got != tt.want {
+				t.Errorf("compareValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTable_fanOut_inTx(t *testing.T) {
+	table := newTestFanOutTable(t)
+
+	err := table.Database.WithTx(context.Background(), func(tx *Tx) error {
+		txTable := tx.Table(table)
+		records, err := txTable.List(nil, ListOptions{OrderByColumn: "id", Limit: 3})
+		if err != nil {
+			return err
+		}
+		if ids, want := testRecordIDs(records), []int64{1, 2, 3}; !reflect.DeepEqual(ids, want) {
+			t.Errorf("Table.List() in transaction = %v, want %v", ids, want)
+		}
+
+		// 事务中不能同时打开多个分表的查询
+		_, err = txTable.Iterator(nil, ListOptions{OrderByColumn: "id"})
+		var errInvalid *ErrorSQLInvalid
+		if !errors.As(err, &errInvalid) {
+			t.Errorf("Table.Iterator() in transaction error = %v, want ErrorSQLInvalid", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTable_splitValueWithHyphen(t *testing.T) {
+	table := newTestFanOutTable(t)
+	if _, err := table.Insert(&testFanOutRecord{ID: 7, Group: "x-y", Name: "n7"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Save(&testFanOutRecord{ID: 7, Group: "x-y", Name: "m7"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got testFanOutRecord
+	if err := table.Get(SelectorFilter{"grp": "x-y"}, &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := (testFanOutRecord{ID: 7, Group: "x-y", Name: "m7"}); got != want {
+		t.Errorf("Table.Get() = %+v, want %+v", got, want)
+	}
+	if count, err := table.Count(nil); err != nil || count != 7 {
+		t.Errorf("Table.Count() = %v, %v, want 7", count, err)
+	}
+}
+
+func TestTable_splitCandidates(t *testing.T) {
+	table := &Table{TableName: "test_fanout"}
+	table.SetRowModel(func() interface{} { return &testFanOutRecord{} })
+
+	tests := []struct {
+		name   string
+		filter RowFilter
+		want   map[string]bool
+	}{
+		{"nil", nil, nil},
+		{"col list", ColListFilter{Col: "grp", Values: []interface{}{"a"}}, map[string]bool{"test_fanout_a": true}},
+		{"in", InFilter{Col: "grp", Values: []interface{}{"a", "b"}}, map[string]bool{"test_fanout_a": true, "test_fanout_b": true}},
+		{"not in", NotInFilter{Col: "grp", Values: []interface{}{"a"}}, nil},
+		{"other column", InFilter{Col: "name", Values: []interface{}{"a"}}, nil},
+		{
+			"in and",
+			RowFilterAnd{CompareFilter{Col: "id", Op: OpGt, Value: 1}, InFilter{Col: "grp", Values: []interface{}{"c"}}},
+			map[string]bool{"test_fanout_c": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := table.splitCandidates(tt.filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Table.splitCandidates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTable_fanOut_canceled(t *testing.T) {
+	table := &Table{TableName: "test_fanout", FanOutConcurrency: 2}
+	errFailed := errors.New("failed")
+
+	var started int32
+	err := table.fanOut(context.Background(), []string{"t_1", "t_2", "t_3", "t_4"},
+		func(ctx context.Context, i int, _ string) error {
+			atomic.AddInt32(&started, 1)
+			if i == 1 {
+				return errFailed
+			}
+			// 其它分表在首个错误后被取消
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(3 * time.Second):
+				return nil
+			}
+		})
+	if !errors.Is(err, errFailed) {
+		t.Errorf("Table.fanOut() error = %v, want %v", err, errFailed)
+	}
+	if n := atomic.LoadInt32(&started); n != 2 {
+		t.Errorf("Table.fanOut() started %d shards, want 2", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = table.fanOut(ctx, []string{"t_1"}, func(context.Context, int, string) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Table.fanOut() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestTable_shardListOptions(t *testing.T) {
+	table := newTestFanOutTable(t)
+	tests := []struct {
+		name  string
+		terms []OrderTerm
+		want  []string
+	}{
+		{"not null column", []OrderTerm{{Col: "id", Desc: true}}, []string{"id DESC"}},
+		{"nullable ascending", []OrderTerm{{Col: "name"}}, []string{"name IS NULL DESC, name"}},
+		{"nullable descending", []OrderTerm{{Col: "name", Desc: true}}, []string{"name IS NULL, name DESC"}},
+		{"nulls first", []OrderTerm{{Col: "name", Desc: true, NullsFirst: true}}, []string{"name IS NULL DESC, name DESC"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.shardListOptions(ListOptions{OrderBy: tt.terms}, 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var terms []string
+			for _, o := range got.OrderBy {
+				terms = append(terms, o.String())
+			}
+			if !reflect.DeepEqual(terms, tt.want) {
+				t.Errorf("Table.shardListOptions() order by = %q, want %q", terms, tt.want)
+			}
+		})
+	}
+}
+
+// caseInsensitiveDialect dialect for unit testing, strings are not ordered by bytes in it.
+type caseInsensitiveDialect struct {
+	SQLiteDialect
+}
+
+func (*caseInsensitiveDialect) BinaryStringOrder() bool {
+	return false
+}
+
+func TestTable_List_fanOutCollation(t *testing.T) {
+	table := newTestFanOutTable(t)
+	replaceTestDialect(t, DriverSQLite3, func(Dialect) Dialect { return new(caseInsensitiveDialect) })
+
+	var errInvalid *ErrorSQLInvalid
+	if _, err := table.List(nil, ListOptions{OrderByColumn: "name"}); !errors.As(err, &errInvalid) {
+		t.Errorf("Table.List() ordered by string column error = %v, want ErrorSQLInvalid", err)
+	}
+	if _, err := table.Iterator(nil, ListOptions{OrderByColumn: "name"}); !errors.As(err, &errInvalid) {
+		t.Errorf("Table.Iterator() ordered by string column error = %v, want ErrorSQLInvalid", err)
+	}
+	if got, err := table.List(nil, ListOptions{OrderByColumn: "id", Limit: 2}); err != nil || len(got) != 2 {
+		t.Errorf("Table.List() ordered by id = %v, %v, want 2 records", got, err)
+	}
+	// 单个分表由数据库排序
+	if got, err := table.List(SelectorFilter{"grp": "a"}, ListOptions{OrderByColumn: "name"}); err != nil || len(got) != 3 {
+		t.Errorf("Table.List() of single shard = %v, %v, want 3 records", got, err)
+	}
+}
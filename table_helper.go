@@ -1,20 +1,27 @@
 package sqlm
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
 )
 
+// setPatternPrefix prefix of the named parameters for values to set in update statement.
+const setPatternPrefix = "set_"
+
 type targetQuery struct {
 	targetTable string
 	query       string
+	returning   bool // the query returns inserted id by result rows.
 }
 
 func (t *Table) initSchema() {
@@ -46,10 +53,54 @@ func (t *Table) scanRow(rows *sqlx.Rows) (interface{}, error) {
 	return record, nil
 }
 
-func (t *Table) inserts(records []interface{}) ([]int64, error) {
+// inserts records to table in multi-row statements, records are inserted one by one
+// when the batch size is 1 or the driver has no dialect.
+func (t *Table) inserts(ctx context.Context, records []interface{}) ([]int64, error) {
+	for _, r := range records {
+		t.initVersion(r)
+	}
+
+	dialect, err := t.dialect()
+	if err != nil || t.InsertBatchSize == 1 || len(t.getSchema().InsertCols()) == 0 {
+		return t.insertsOneByOne(ctx, records)
+	}
+	batchDialect, ok := dialect.(BatchDialect)
+	if !ok {
+		return t.insertsOneByOne(ctx, records)
+	}
+
+	batches, err := t.groupInsertBatches(records)
+	if err != nil {
+		return make([]int64, 0), err
+	}
+
+	ret := make([]int64, len(records))
+	inserted := make([]bool, len(records))
+	for _, b := range batches {
+		for _, chunk := range t.splitInsertBatch(batchDialect, b) {
+			ids, err := t.insertChunk(ctx, batchDialect, chunk)
+			if err != nil {
+				// same as inserting one by one, return ids of the leading records which are inserted.
+				done := 0
+				for done < len(inserted) && inserted[done] {
+					done++
+				}
+				return ret[:done], err
+			}
+			for i, id := range ids {
+				ret[chunk.indexes[i]] = id
+				inserted[chunk.indexes[i]] = true
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+func (t *Table) insertsOneByOne(ctx context.Context, records []interface{}) ([]int64, error) {
 	ret := make([]int64, 0)
 	for _, r := range records {
-		id, err := t.insert(r)
+		id, err := t.insert(ctx, r)
 		if err != nil {
 			return ret, err
 		}
@@ -62,14 +113,20 @@ func (t *Table) inserts(records []interface{}) ([]int64, error) {
 
 // insert records to table.
 // 	if has dup keys record, then return error.
-func (t *Table) insert(record interface{}) (int64, error) {
+func (t *Table) insert(ctx context.Context, record interface{}) (int64, error) {
+	t.initVersion(record)
+
 	insertQuery, err := t.composeInsertQuery(record)
 	if err != nil {
 		return 0, err
 	}
 
 	// 语句执行
-	ret, err := t.execWithAutoCreate(insertQuery, record)
+	if insertQuery.returning {
+		return t.queryIDWithAutoCreate(ctx, insertQuery, record)
+	}
+
+	ret, err := t.execWithAutoCreate(ctx, insertQuery, record)
 	if err == nil && ret != nil {
 		insertID, _ := ret.LastInsertId()
 		return insertID, nil
@@ -78,33 +135,70 @@ func (t *Table) insert(record interface{}) (int64, error) {
 	return 0, err
 }
 
-func (t *Table) composeInsertQuery(record interface{}) (*targetQuery, error) {
-	var insertPatterns []string
-	insertKeys := t.getSchema().InsertCols()
-	for _, k := range insertKeys {
-		insertPatterns = append(insertPatterns, ":"+k)
+func (t *Table) upserts(ctx context.Context, records []interface{}) ([]int64, error) {
+	ret := make([]int64, 0)
+	for _, r := range records {
+		id, err := t.upsert(ctx, r)
+		if err != nil {
+			return ret, err
+		}
+
+		ret = append(ret, id)
 	}
-	insertKeysStr := strings.Join(insertKeys, ",")
-	insertValPatternStr := strings.Join(insertPatterns, ",")
 
+	return ret, nil
+}
+
+// upsert record to table, the exist record with same primary or unique keys is updated.
+func (t *Table) upsert(ctx context.Context, record interface{}) (int64, error) {
+	t.initVersion(record)
+
+	targetTable, err := t.getSchema().TargetName(record)
+	if err != nil {
+		return 0, err
+	}
+	query, err := t.getSchema().UpsertSQL(targetTable)
+	if err != nil {
+		return 0, err
+	}
+	upsertQuery := &targetQuery{targetTable, query, strings.Contains(query, " RETURNING ")}
+
+	// 语句执行
+	if upsertQuery.returning {
+		return t.queryIDWithAutoCreate(ctx, upsertQuery, record)
+	}
+
+	ret, err := t.execWithAutoCreate(ctx, upsertQuery, record)
+	if err != nil || ret == nil {
+		return 0, err
+	}
+	// mysql reports 2 affected rows for the updated record and 0 for the unchanged, LastInsertId is not their id.
+	if affected, _ := ret.RowsAffected(); affected != 1 {
+		return 0, nil
+	}
+	insertID, _ := ret.LastInsertId()
+
+	return insertID, nil
+}
+
+func (t *Table) composeInsertQuery(record interface{}) (*targetQuery, error) {
 	// 语句组装
-	var query string
-	queryTpl := "INSERT INTO %s (%s) VALUES (%s)"
 	targetTable, err := t.getSchema().TargetName(record)
 	if err != nil {
 		return nil, err
 	}
-	query = fmt.Sprintf(queryTpl, targetTable, insertKeysStr, insertValPatternStr)
+	query := t.getSchema().InsertSQL(targetTable)
+	returning := strings.Contains(query, " RETURNING ")
 
-	return &targetQuery{targetTable, query}, nil
+	return &targetQuery{targetTable, query, returning}, nil
 }
 
-func (t *Table) save(record interface{}) error {
+func (t *Table) save(ctx context.Context, record interface{}) error {
 	// 更新部分组装
 	updateFields := t.getSchema().UpdateColsWhenDup()
 	var updatePatterns []string
 	for _, k := range updateFields {
-		updatePatterns = append(updatePatterns, k+"=:"+k)
+		updatePatterns = append(updatePatterns, t.getSchema().quote(k)+"=:"+k)
 	}
 
 	// 过滤条件组装
@@ -124,7 +218,20 @@ func (t *Table) save(record interface{}) error {
 		return &ErrorSQLInvalid{Message: "table schema should has one key col or primary col setted"}
 	}
 	for _, k := range pCols {
-		wherePatterns = append(wherePatterns, k+"=:"+k)
+		wherePatterns = append(wherePatterns, t.getSchema().quote(k)+"=:"+k)
+	}
+
+	// 已软删除的记录不更新, 避免被复活
+	if softDeleteCol := t.getSchema().SoftDeleteCol(); softDeleteCol != "" {
+		wherePatterns = append(wherePatterns, t.getSchema().quote(softDeleteCol)+" IS NULL")
+	}
+
+	// 乐观锁: 版本一致时才更新, 同时版本加一
+	versionCol := t.getSchema().VersionCol()
+	if versionCol != "" {
+		quoted := t.getSchema().quote(versionCol)
+		updatePatterns = append(updatePatterns, fmt.Sprintf("%s=%s+1", quoted, quoted))
+		wherePatterns = append(wherePatterns, quoted+"=:"+versionCol)
 	}
 
 	// 整体语句组合
@@ -134,32 +241,39 @@ func (t *Table) save(record interface{}) error {
 	}
 	sets := strings.Join(updatePatterns, ",")
 	whereConditionStr := strings.Join(wherePatterns, " AND ")
-	query := fmt.Sprintf("%s %s %s %s %s %s", SQLKeyUpdate, targetTable, SQLKeySet, sets, SQLKeyWhere, whereConditionStr)
+	query := fmt.Sprintf("%s %s %s %s %s %s", SQLKeyUpdate, t.getSchema().quoteTable(targetTable), SQLKeySet, sets, SQLKeyWhere, whereConditionStr)
 
-	con, err := t.Con()
+	ext, err := t.executor()
 	if err != nil {
 		return err
 	}
 
 	// 执行
-	_, execErr := con.NamedExec(query, record)
-	return execErr
+	ret, execErr := t.namedExec(ctx, ext, query, record)
+	if execErr != nil || versionCol == "" {
+		return execErr
+	}
+
+	return t.checkVersionSaved(ret, targetTable, record)
 }
 
-// update records in Table.
-func (t *Table) update(filter RowFilter, updatePayload interface{}, updateFields []string) (int64, error) {
+// update records in Table, values are keyed by column name.
+func (t *Table) update(ctx context.Context, filter RowFilter, updateFields []string, values map[string]interface{}) (int64, error) {
 	var rowsAffect int64
 
 	// 计算过滤条件
-	whereFormatter, err := composeWhereConditionStrForUpdate(filter)
+	where, err := composeWhereForUpdate(t, filter)
 	if err != nil {
 		return rowsAffect, err
 	}
 
-	// 计算更新内容
+	// 计算更新内容, 更新值的参数名加前缀, 过滤条件中同名的参数会在合并时重命名
+	args := &SQLWhere{Patterns: make(map[string]interface{})}
 	var updatePatterns []string
 	for _, k := range updateFields {
-		updatePatterns = append(updatePatterns, k+"=:"+k)
+		pattern := setPatternPrefix + k
+		updatePatterns = append(updatePatterns, t.getSchema().quote(k)+"=:"+pattern)
+		args.Patterns[pattern] = values[k]
 	}
 
 	// 组合sql语句
@@ -167,21 +281,25 @@ func (t *Table) update(filter RowFilter, updatePayload interface{}, updateFields
 	if err != nil {
 		return 0, err
 	}
-	query := fmt.Sprintf("%s %s %s %s", SQLKeyUpdate, targetTable, SQLKeySet, strings.Join(updatePatterns, ","))
-	if whereFormatter != "" {
-		query += " where " + whereFormatter
+	query := fmt.Sprintf("%s %s %s %s", SQLKeyUpdate, t.getSchema().quoteTable(targetTable), SQLKeySet, strings.Join(updatePatterns, ","))
+	if where != nil && where.Format != "" {
+		whereFormat, err := mergeWhere(args, where)
+		if err != nil {
+			return 0, &ErrorSQLInvalid{"where条件组装失败", err}
+		}
+		query += " where " + whereFormat
 	}
 
 	// 执行
-	ret, execErr := t.execWhenExist(query, updatePayload)
+	ret, execErr := t.execWhenExist(ctx, query, args.Patterns)
 	if ret != nil {
 		rowsAffect, _ = ret.RowsAffected()
 	}
 	return rowsAffect, execErr
 }
 
-func (t *Table) deleteRows(filter RowFilter) (sql.Result, error) {
-	where, err := filter.WherePattern()
+func (t *Table) deleteRows(ctx context.Context, filter RowFilter) (sql.Result, error) {
+	where, err := t.wherePattern(filter)
 	if err != nil {
 		return nil, &ErrorSQLInvalid{"where条件组装失败", err}
 	}
@@ -197,39 +315,203 @@ func (t *Table) deleteRows(filter RowFilter) (sql.Result, error) {
 	if err != nil {
 		return nil, err
 	}
-	query := fmt.Sprintf("%s %s %s %s %s", SQLKeyDelete, SQLKeyFrom, targetTable, SQLKeyWhere, where.Format)
+	query := fmt.Sprintf("%s %s %s %s %s", SQLKeyDelete, SQLKeyFrom, t.getSchema().quoteTable(targetTable), SQLKeyWhere, where.Format)
 
-	return t.execWhenExist(query, where.Patterns)
+	return t.execWhenExist(ctx, query, where.Patterns)
 }
 
-func (t *Table) execWhenExist(query string, arg interface{}) (ret sql.Result, err error) {
+func (t *Table) execWhenExist(ctx context.Context, query string, arg interface{}) (ret sql.Result, err error) {
 	exec := func(et *Table) error {
-		con, conErr := et.Con()
+		ext, conErr := et.executor()
 		if conErr == nil {
-			ret, conErr = con.NamedExec(query, arg)
+			ret, conErr = et.namedExec(ctx, ext, query, arg)
 		}
 
 		return conErr
 	}
 
-	err = doWhenTableExist(t, exec)
+	err = doWhenTableExist(ctx, t, exec)
 	return ret, err
 }
 
-func (t *Table) execWithAutoCreate(query *targetQuery, arg interface{}) (ret sql.Result, err error) {
+func (t *Table) execWithAutoCreate(ctx context.Context, query *targetQuery, arg interface{}) (ret sql.Result, err error) {
 	exec := func(et *Table) error {
-		con, errCon := et.Con()
+		ext, errCon := et.executor()
 		if errCon == nil {
-			ret, errCon = con.NamedExec(query.query, arg)
+			ret, errCon = et.namedExec(ctx, ext, query.query, arg)
 		}
 
 		return errCon
 	}
 
-	err = doWithAutoCreate(t, query.targetTable, exec)
+	err = doWithAutoCreate(ctx, t, query.targetTable, exec)
 	return ret, err
 }
 
+// executor return the transaction when table is bound to one, otherwise the db connection.
+func (t *Table) executor() (sqlx.ExtContext, error) {
+	if t.tx != nil {
+		return t.tx.tx, nil
+	}
+
+	return t.Con()
+}
+
+// ddlExecutor return executor for table creating.
+// Some databases commit the transaction implicitly on DDL, such as mysql, so use the db connection for them.
+func (t *Table) ddlExecutor() (sqlx.ExtContext, error) {
+	if t.tx == nil {
+		return t.executor()
+	}
+
+	dialect, err := t.dialect()
+	if err != nil {
+		return nil, err
+	}
+	if dialect.DDLCommitsTx() {
+		return t.Con()
+	}
+
+	return t.executor()
+}
+
+// wherePattern compose where statement of the filter for table's dialect.
+func (t *Table) wherePattern(filter RowFilter) (*SQLWhere, error) {
+	dialect, err := t.dialect()
+	if err != nil {
+		return filter.WherePattern()
+	}
+
+	return WherePatternWithDialect(filter, dialect)
+}
+
+// dialect return the sql dialect of table's database driver.
+func (t *Table) dialect() (Dialect, error) {
+	if t.Database == nil {
+		return nil, errors.New("database not setted")
+	}
+
+	return GetDialect(t.Driver)
+}
+
+// namedExec bind the named query in the placeholder style of the dialect, then execute it.
+func (t *Table) namedExec(ctx context.Context, ext sqlx.ExtContext, query string, arg interface{}) (sql.Result, error) {
+	q, args, err := sqlx.BindNamed(t.bindType(ext), query, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return ext.ExecContext(ctx, q, args...)
+}
+
+// namedQuery bind the named query in the placeholder style of the dialect, then query with it.
+func (t *Table) namedQuery(ctx context.Context, ext sqlx.ExtContext, query string, arg interface{}) (*sqlx.Rows, error) {
+	q, args, err := sqlx.BindNamed(t.bindType(ext), query, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return ext.QueryxContext(ctx, q, args...)
+}
+
+func (t *Table) bindType(ext sqlx.ExtContext) int {
+	if dialect, err := t.dialect(); err == nil {
+		return dialect.BindType()
+	}
+
+	return sqlx.BindType(ext.DriverName())
+}
+
+// isTableNotExist report whether the error is caused by table not existed.
+func (t *Table) isTableNotExist(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if dialect, dialectErr := t.dialect(); dialectErr == nil {
+		return dialect.IsTableNotExist(err)
+	}
+
+	return regexp.MustCompile(TableNotExistErrorRegex).MatchString(err.Error())
+}
+
+// queryIDWithAutoCreate run the insert query which returns inserted id by result rows.
+func (t *Table) queryIDWithAutoCreate(ctx context.Context, query *targetQuery, arg interface{}) (id int64, err error) {
+	exec := func(et *Table) error {
+		ext, errCon := et.executor()
+		if errCon != nil {
+			return errCon
+		}
+
+		rows, errCon := et.namedQuery(ctx, ext, query.query, arg)
+		if errCon != nil {
+			return errCon
+		}
+		defer rows.Close()
+
+		if rows.Next() {
+			errCon = rows.Scan(&id)
+		}
+		if errCon == nil {
+			errCon = rows.Err()
+		}
+
+		return errCon
+	}
+
+	err = doWithAutoCreate(ctx, t, query.targetTable, exec)
+	return id, err
+}
+
+// queryIDsWithAutoCreate query the ids of all inserted rows returned by the statement.
+func (t *Table) queryIDsWithAutoCreate(ctx context.Context, query *targetQuery, arg interface{}) (ids []int64, err error) {
+	exec := func(et *Table) error {
+		ids = ids[:0]
+		ext, errCon := et.executor()
+		if errCon != nil {
+			return errCon
+		}
+
+		rows, errCon := et.namedQuery(ctx, ext, query.query, arg)
+		if errCon != nil {
+			return errCon
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id int64
+			if errCon = rows.Scan(&id); errCon != nil {
+				return errCon
+			}
+			ids = append(ids, id)
+		}
+
+		return rows.Err()
+	}
+
+	err = doWithAutoCreate(ctx, t, query.targetTable, exec)
+	return ids, err
+}
+
+// guard run do in a savepoint when table is bound to a transaction which will be aborted
+// once a statement failed, such as postgresql.
+func (t *Table) guard(ctx context.Context, do func(t *Table) error) error {
+	if !t.needSavepoint() {
+		return do(t)
+	}
+
+	return t.tx.guard(ctx, func() error { return do(t) })
+}
+
+// needSavepoint report whether the statements should be guarded by savepoint.
+func (t *Table) needSavepoint() bool {
+	if t.tx == nil {
+		return false
+	}
+	dialect, err := t.dialect()
+	return err == nil && dialect.ErrorAbortsTx()
+}
+
 func (t *Table) getSchema() *TableSchema {
 	t.once.Do(t.initSchema)
 
@@ -246,27 +528,24 @@ func (t *Table) uniqWhereFormatter() string {
 	}
 
 	for _, k := range pCols {
-		whereFormater = append(whereFormater, fmt.Sprintf("%s=:%s", k, k))
+		whereFormater = append(whereFormater, fmt.Sprintf("%s=:%s", t.getSchema().quote(k), k))
 	}
 	return strings.Join(whereFormater, " AND ")
 }
 
-func doWhenTableExist(t *Table, do func(t *Table) error) error {
-	tableNotExistErrMsgReg := regexp.MustCompile(TableNotExistErrorRegex)
-	err := do(t)
+func doWhenTableExist(ctx context.Context, t *Table, do func(t *Table) error) error {
+	err := t.guard(ctx, do)
 
-	if err != nil && !tableNotExistErrMsgReg.MatchString(err.Error()) {
+	if err != nil && !t.isTableNotExist(err) {
 		return err
 	}
 
 	return nil
 }
 
-func doWithAutoCreate(t *Table, targetTable string, do func(t *Table) error) error {
-	tableNotExistErrMsgReg := regexp.MustCompile(TableNotExistErrorRegex)
-
-	err := do(t)
-	if err == nil || !tableNotExistErrMsgReg.MatchString(err.Error()) {
+func doWithAutoCreate(ctx context.Context, t *Table, targetTable string, do func(t *Table) error) error {
+	err := t.guard(ctx, do)
+	if err == nil || !t.isTableNotExist(err) {
 		return err
 	}
 
@@ -275,12 +554,12 @@ func doWithAutoCreate(t *Table, targetTable string, do func(t *Table) error) err
 	schema.Name = targetTable
 	createSQL := schema.CreateSQL()
 
-	con, err := t.Con()
+	ext, err := t.ddlExecutor()
 	if err != nil {
 		return err
 	}
 
-	_, err = con.Exec(createSQL)
+	_, err = ext.ExecContext(ctx, createSQL)
 	if err != nil {
 		errTpl := "try to auto create table (%s) failed:\nsql: %s\nerror: %w"
 		return fmt.Errorf(errTpl, targetTable, createSQL, err)
@@ -288,39 +567,66 @@ func doWithAutoCreate(t *Table, targetTable string, do func(t *Table) error) err
 
 	// 表创建成功后重新执行
 	err = do(t)
-	if err != nil && !tableNotExistErrMsgReg.MatchString(err.Error()) {
+	if err != nil && !t.isTableNotExist(err) {
 		return fmt.Errorf("error also happened after table auto created: %w", err)
 	}
 
 	return err
 }
 
-func (t *Table) queryWhenExist(query string, arg interface{}) (rows *sqlx.Rows, err error) {
-	exec := func(et *Table) error {
-		con, errCon := et.Con()
-		if errCon == nil {
-			rows, errCon = con.NamedQuery(query, arg)
-		}
+// queryRows the rows of query, the savepoint guarding the query is ended when it's closed
+// because the statements can not run in transaction before the rows closed.
+type queryRows struct {
+	*sqlx.Rows
+	end func(err error) error
+}
 
-		return errCon
+// Close close the rows and end the savepoint, it's safe to call multiple times.
+func (r *queryRows) Close() error {
+	err := r.Rows.Close()
+	if r.end == nil {
+		return err
 	}
 
-	err = doWhenTableExist(t, exec)
-	return rows, err
+	end := r.end
+	r.end = nil
+	if rowsErr := r.Rows.Err(); rowsErr != nil {
+		_ = end(rowsErr)
+		return err
+	}
+	if endErr := end(nil); err == nil {
+		err = endErr
+	}
+
+	return err
 }
 
-func formatCondition(v interface{}) string {
-	switch v := v.(type) {
-	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
-		return fmt.Sprintf("%v", v)
-	case bool:
-		if v {
-			return "1"
+// queryWhenExist query in the table, the rows are nil when the table not exists.
+func (t *Table) queryWhenExist(ctx context.Context, query string, arg interface{}) (*queryRows, error) {
+	ext, err := t.executor()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &queryRows{}
+	if t.needSavepoint() {
+		if ret.end, err = t.tx.savepoint(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	ret.Rows, err = t.namedQuery(ctx, ext, query, arg)
+	if err != nil && ret.end != nil {
+		err = ret.end(err)
+	}
+	if err != nil {
+		if t.isTableNotExist(err) {
+			return nil, nil
 		}
-		return "0"
-	default:
-		return fmt.Sprintf("'%v'", v)
+		return nil, err
 	}
+
+	return ret, nil
 }
 
 func loadDataForUpdate(t *Table, src map[string]interface{}, dest interface{}) ([]string, error) {
@@ -330,6 +636,7 @@ func loadDataForUpdate(t *Table, src map[string]interface{}, dest interface{}) (
 		for k := range src {
 			updateFields = append(updateFields, k)
 		}
+		sort.Strings(updateFields)
 		return updateFields, nil
 	}
 
@@ -346,24 +653,46 @@ func loadDataForUpdate(t *Table, src map[string]interface{}, dest interface{}) (
 	return updateFields, nil
 }
 
-func composeWhereConditionStrForUpdate(filter RowFilter) (string, error) {
-	var ret string
-	where, err := filter.WherePattern()
-	if err != nil {
-		return ret, &ErrorSQLInvalid{"where条件组装失败", err}
+// loadValuesForUpdate return values keyed by column name of the update fields,
+// values are read from the loaded dest or the src directly when dest is nil.
+func loadValuesForUpdate(src map[string]interface{}, dest interface{}, updateFields []string) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(updateFields))
+	if dest == nil {
+		for _, k := range updateFields {
+			values[k] = src[k]
+		}
+		return values, nil
 	}
-	if where == nil {
-		return ret, nil
+
+	v := reflect.Indirect(reflect.ValueOf(dest))
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("update payload should be a struct or struct pointer: %T", dest)
 	}
-	if where.Join != nil {
-		return ret, &ErrorSQLInvalid{Message: "update中的不允许where中存在联合条件"}
+
+	fields := reflectx.NewMapper(DBSchemaTag).FieldMap(v)
+	for _, k := range updateFields {
+		f, ok := fields[k]
+		if !ok {
+			return nil, fmt.Errorf("column %s not found in update payload: %T", k, dest)
+		}
+		values[k] = f.Interface()
 	}
 
-	ret = where.Format
-	for k, v := range where.Patterns {
-		re := regexp.MustCompile(":" + k + `\b`)
-		ret = re.ReplaceAllString(ret, formatCondition(v))
+	return values, nil
+}
+
+func composeWhereForUpdate(t *Table, filter RowFilter) (*SQLWhere, error) {
+	if filter == nil {
+		return nil, nil
 	}
 
-	return ret, nil
+	where, err := t.wherePattern(filter)
+	if err != nil {
+		return nil, &ErrorSQLInvalid{"where条件组装失败", err}
+	}
+	if where != nil && where.Join != nil {
+		return nil, &ErrorSQLInvalid{Message: "update中的不允许where中存在联合条件"}
+	}
+
+	return where, nil
 }
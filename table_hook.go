@@ -1,11 +1,22 @@
 package sqlm
 
+import (
+	"context"
+	"fmt"
+)
+
 // InsertHookFunc hook for table insert record operation
 type InsertHookFunc func(t *Table, record interface{}) error
 
 // InsertsHookFunc hook for table inserts records operation
 type InsertsHookFunc func(t *Table, records []interface{}) error
 
+// UpsertHookFunc hook for table upsert record operation
+type UpsertHookFunc func(t *Table, record interface{}) error
+
+// UpsertsHookFunc hook for table upsert records operation
+type UpsertsHookFunc func(t *Table, records []interface{}) error
+
 // SaveHookFunc hook for table single record update operation
 type SaveHookFunc func(t *Table, record interface{}) error
 
@@ -15,6 +26,27 @@ type UpdateHookFunc func(t *Table, rf RowFilter, parts map[string]interface{}) e
 // DeleteHookFunc hook for table delete records operation
 type DeleteHookFunc func(t *Table, rf RowFilter) error
 
+// InsertContextHookFunc hook for table insert record operation with context
+type InsertContextHookFunc func(ctx context.Context, t *Table, record interface{}) error
+
+// InsertsContextHookFunc hook for table inserts records operation with context
+type InsertsContextHookFunc func(ctx context.Context, t *Table, records []interface{}) error
+
+// UpsertContextHookFunc hook for table upsert record operation with context
+type UpsertContextHookFunc func(ctx context.Context, t *Table, record interface{}) error
+
+// UpsertsContextHookFunc hook for table upsert records operation with context
+type UpsertsContextHookFunc func(ctx context.Context, t *Table, records []interface{}) error
+
+// SaveContextHookFunc hook for table single record update operation with context
+type SaveContextHookFunc func(ctx context.Context, t *Table, record interface{}) error
+
+// UpdateContextHookFunc hook for table records update operation with context
+type UpdateContextHookFunc func(ctx context.Context, t *Table, rf RowFilter, parts map[string]interface{}) error
+
+// DeleteContextHookFunc hook for table delete records operation with context
+type DeleteContextHookFunc func(ctx context.Context, t *Table, rf RowFilter) error
+
 // TableOperateHook hook for single kind operation
 type TableOperateHook struct {
 	Before []interface{}
@@ -34,6 +66,8 @@ func (h *TableOperateHook) Merge(other *TableOperateHook) {
 type TableHooks struct {
 	Insert  TableOperateHook
 	Inserts TableOperateHook
+	Upsert  TableOperateHook
+	Upserts TableOperateHook
 	Save    TableOperateHook
 	Update  TableOperateHook
 	Delete  TableOperateHook
@@ -47,7 +81,125 @@ func (h *TableHooks) Merge(other *TableHooks) {
 
 	h.Insert.Merge(&other.Insert)
 	h.Inserts.Merge(&other.Insert)
+	h.Upsert.Merge(&other.Upsert)
+	h.Upserts.Merge(&other.Upserts)
 	h.Save.Merge(&other.Insert)
 	h.Update.Merge(&other.Insert)
 	h.Delete.Merge(&other.Insert)
 }
+
+// callRecordHooks call insert/upsert/save hooks, which accept single record.
+func callRecordHooks(ctx context.Context, hooks []interface{}, t *Table, record interface{}) error {
+	for _, hook := range hooks {
+		var err error
+
+		switch h := hook.(type) {
+		case InsertHookFunc:
+			err = h(t, record)
+		case UpsertHookFunc:
+			err = h(t, record)
+		case SaveHookFunc:
+			err = h(t, record)
+		case func(*Table, interface{}) error:
+			err = h(t, record)
+		case InsertContextHookFunc:
+			err = h(ctx, t, record)
+		case UpsertContextHookFunc:
+			err = h(ctx, t, record)
+		case SaveContextHookFunc:
+			err = h(ctx, t, record)
+		case func(context.Context, *Table, interface{}) error:
+			err = h(ctx, t, record)
+		default:
+			err = fmt.Errorf("unsupported hook type: %T", hook)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// callInsertsHooks call batch insert/upsert hooks.
+func callInsertsHooks(ctx context.Context, hooks []interface{}, t *Table, records []interface{}) error {
+	for _, hook := range hooks {
+		var err error
+
+		switch h := hook.(type) {
+		case InsertsHookFunc:
+			err = h(t, records)
+		case UpsertsHookFunc:
+			err = h(t, records)
+		case func(*Table, []interface{}) error:
+			err = h(t, records)
+		case InsertsContextHookFunc:
+			err = h(ctx, t, records)
+		case UpsertsContextHookFunc:
+			err = h(ctx, t, records)
+		case func(context.Context, *Table, []interface{}) error:
+			err = h(ctx, t, records)
+		default:
+			err = fmt.Errorf("unsupported hook type: %T", hook)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// callUpdateHooks call update hooks.
+func callUpdateHooks(ctx context.Context, hooks []interface{}, t *Table, rf RowFilter, parts map[string]interface{}) error {
+	for _, hook := range hooks {
+		var err error
+
+		switch h := hook.(type) {
+		case UpdateHookFunc:
+			err = h(t, rf, parts)
+		case func(*Table, RowFilter, map[string]interface{}) error:
+			err = h(t, rf, parts)
+		case UpdateContextHookFunc:
+			err = h(ctx, t, rf, parts)
+		case func(context.Context, *Table, RowFilter, map[string]interface{}) error:
+			err = h(ctx, t, rf, parts)
+		default:
+			err = fmt.Errorf("unsupported hook type: %T", hook)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// callDeleteHooks call delete hooks.
+func callDeleteHooks(ctx context.Context, hooks []interface{}, t *Table, rf RowFilter) error {
+	for _, hook := range hooks {
+		var err error
+
+		switch h := hook.(type) {
+		case DeleteHookFunc:
+			err = h(t, rf)
+		case func(*Table, RowFilter) error:
+			err = h(t, rf)
+		case DeleteContextHookFunc:
+			err = h(ctx, t, rf)
+		case func(context.Context, *Table, RowFilter) error:
+			err = h(ctx, t, rf)
+		default:
+			err = fmt.Errorf("unsupported hook type: %T", hook)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
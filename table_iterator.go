@@ -0,0 +1,144 @@
+package sqlm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrStopIterate returned by the callback of Table#Iterate() to stop iterating without error.
+var ErrStopIterate = errors.New("stop iterate")
+
+// RowIterator iterate records of query result lazily, records are scanned one by one.
+// It should be closed after using to release the db connection.
+type RowIterator struct {
+	table  *Table
+	rows   *queryRows
+	shards *shardRows // rows of shard tables in fan-out iterating.
+	record interface{}
+	err    error
+}
+
+// Next prepare the next record for reading by Record(), it returns false when no more records or error occurred.
+func (it *RowIterator) Next() bool {
+	it.record = nil
+	if it.shards != nil && it.err == nil {
+		it.record, it.err = it.shards.next()
+		if it.record == nil || it.err != nil {
+			it.record = nil
+			it.Close()
+			return false
+		}
+		return true
+	}
+	if it.rows == nil || it.err != nil {
+		return false
+	}
+
+	if !it.rows.Next() {
+		it.err = it.rows.Err()
+		it.Close()
+		return false
+	}
+
+	it.record, it.err = it.table.scanRow(it.rows.Rows)
+	if it.err != nil {
+		it.Close()
+		return false
+	}
+
+	return true
+}
+
+// Record return current record scanned by Next().
+func (it *RowIterator) Record() interface{} {
+	return it.record
+}
+
+// Err return the error occurred during iterating.
+func (it *RowIterator) Err() error {
+	return it.err
+}
+
+// Close release the db connection, it's safe to call multiple times.
+func (it *RowIterator) Close() error {
+	if it.shards != nil {
+		err := it.shards.close()
+		it.shards = nil
+		return err
+	}
+	if it.rows == nil {
+		return nil
+	}
+
+	err := it.rows.Close()
+	it.rows = nil
+	return err
+}
+
+// Iterator return iterator of records from Table, it's empty when table not exist.
+func (t *Table) Iterator(filter RowFilter, options ListOptions) (*RowIterator, error) {
+	return t.IteratorContext(context.Background(), filter, options)
+}
+
+// IteratorContext return iterator of records from Table with context.
+// For the split table, it iterates all shard tables when the split column values are absent or given as list,
+// the records are merged by the sort keys with all shards queried at the same time, so the shards should not be
+// more than Table#FanOutConcurrency and the maximum open connections of database.
+func (t *Table) IteratorContext(ctx context.Context, filter RowFilter, options ListOptions) (*RowIterator, error) {
+	if options.Cursor != "" {
+		return nil, &ErrorSQLInvalid{Message: "cursor is not supported in iterating, use ListPage instead"}
+	}
+
+	tables, fanOut, err := t.queryTargets(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if fanOut {
+		shards, err := t.newShardRows(ctx, tables, filter, options)
+		if err != nil {
+			return nil, err
+		}
+		return &RowIterator{table: t, shards: shards}, nil
+	}
+
+	query, wherePatterns, err := t.getSchema().selectSQL(tables[0], filter, options)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, queryErr := t.queryWhenExist(ctx, query.String(), wherePatterns)
+	if queryErr != nil {
+		return nil, fmt.Errorf("query failed :%w\nsql: %s\nwherePatterns: %v", queryErr, &query, wherePatterns)
+	}
+
+	return &RowIterator{table: t, rows: rows}, nil
+}
+
+// Iterate records from Table one by one, without loading all of them in memory.
+// Return ErrStopIterate in fn to stop iterating early, other errors are returned as it is.
+func (t *Table) Iterate(filter RowFilter, options ListOptions, fn func(record interface{}) error) error {
+	return t.IterateContext(context.Background(), filter, options, fn)
+}
+
+// IterateContext iterate records from Table with context.
+func (t *Table) IterateContext(ctx context.Context, filter RowFilter, options ListOptions, fn func(record interface{}) error) error {
+	it, err := t.IteratorContext(ctx, filter, options)
+	if err != nil {
+		return err
+	}
+
+	// 释放db连接
+	defer it.Close()
+
+	for it.Next() {
+		if err := fn(it.Record()); err != nil {
+			if errors.Is(err, ErrStopIterate) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return it.Err()
+}
@@ -0,0 +1,88 @@
+package sqlm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTable_Iterate(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_iterate", &testSimpleRecord{})
+	var records []interface{}
+	for i, score := range []int32{3, 1, 2, 3, 1} {
+		records = append(records, &testSimpleRecord{ID: int64(i + 1), Name: string(rune('a' + i)), Score: score})
+	}
+	if _, err := table.Inserts(records); err != nil {
+		t.Fatal(err)
+	}
+
+	errBreak := errors.New("break")
+	tests := []struct {
+		name    string
+		filter  RowFilter
+		options ListOptions
+		stopAt  int64
+		stopErr error
+		wantIDs []int64
+		wantErr error
+	}{
+		{"all", nil, ListOptions{OrderByColumn: "id"}, 0, nil, []int64{1, 2, 3, 4, 5}, nil},
+		{"filter and order", SelectorFilter{"score": 3}, ListOptions{OrderByColumn: "id", OrderDesc: true}, 0, nil, []int64{4, 1}, nil},
+		{"stop early", nil, ListOptions{OrderByColumn: "id"}, 2, ErrStopIterate, []int64{1, 2}, nil},
+		{"callback error", nil, ListOptions{OrderByColumn: "id"}, 3, errBreak, []int64{1, 2, 3}, errBreak},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotIDs []int64
+			err := table.Iterate(tt.filter, tt.options, func(record interface{}) error {
+				id := record.(*testSimpleRecord).ID
+				gotIDs = append(gotIDs, id)
+				if id == tt.stopAt {
+					return tt.stopErr
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Table.Iterate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(gotIDs, tt.wantIDs) {
+				t.Errorf("Table.Iterate() ids = %v, want %v", gotIDs, tt.wantIDs)
+			}
+		})
+	}
+
+	t.Run("iterator", func(t *testing.T) {
+		it, err := table.Iterator(CompareFilter{Col: "score", Op: OpLt, Value: 3}, ListOptions{OrderByColumn: "id"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer it.Close()
+
+		var gotIDs []int64
+		for it.Next() {
+			gotIDs = append(gotIDs, it.Record().(*testSimpleRecord).ID)
+		}
+		if err := it.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if want := []int64{2, 3, 5}; !reflect.DeepEqual(gotIDs, want) {
+			t.Errorf("RowIterator ids = %v, want %v", gotIDs, want)
+		}
+		if it.Next() || it.Record() != nil {
+			t.Error("RowIterator.Next() should be false after finished")
+		}
+	})
+
+	t.Run("table not exist", func(t *testing.T) {
+		other := &Table{Database: table.Database, TableName: "test_iterate_not_exist"}
+		other.SetRowModel(func() interface{} { return &testSimpleRecord{} })
+
+		it, err := other.Iterator(nil, ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if it.Next() || it.Err() != nil || it.Close() != nil {
+			t.Error("RowIterator of not existed table should be empty")
+		}
+	})
+}
@@ -0,0 +1,266 @@
+package sqlm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// cursorPatternPrefix prefix of the named parameters for cursor values in keyset pagination.
+const cursorPatternPrefix = "cursor_"
+
+// cursorToken is the decoded cursor for keyset pagination.
+type cursorToken struct {
+	Cols   []string          `json:"c"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// keysetFilter filter the rows after the cursor values in keyset pagination,
+// like: `(a > :cursor_0) OR (a = :cursor_0 AND id > :cursor_1)`.
+type keysetFilter struct {
+	terms  []OrderTerm
+	values []interface{}
+}
+
+// EqualPatterns imp for EqualRowFilter interface, no columns are restricted to be equal.
+func (keysetFilter) EqualPatterns() (map[string]interface{}, error) {
+	return nil, nil
+}
+
+// WherePattern imp for RowFilter interface
+func (f keysetFilter) WherePattern() (*SQLWhere, error) {
+	return f.DialectWherePattern(nil)
+}
+
+// DialectWherePattern imp for DialectRowFilter interface
+func (f keysetFilter) DialectWherePattern(d Dialect) (*SQLWhere, error) {
+	if len(f.terms) == 0 || len(f.terms) != len(f.values) {
+		return nil, fmt.Errorf("cursor columns and values are not matched")
+	}
+
+	var ors []string
+	patterns := make(map[string]interface{}, len(f.terms))
+	for i, o := range f.terms {
+		op := ">"
+		if o.Desc {
+			op = "<"
+		}
+
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, fmt.Sprintf("%s = :%s%d", quoteIdentifier(d, f.terms[j].Col), cursorPatternPrefix, j))
+		}
+		ands = append(ands, fmt.Sprintf("%s %s :%s%d", quoteIdentifier(d, o.Col), op, cursorPatternPrefix, i))
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+		patterns[fmt.Sprintf("%s%d", cursorPatternPrefix, i)] = f.values[i]
+	}
+
+	return &SQLWhere{Format: "(" + strings.Join(ors, " OR ") + ")", Patterns: patterns}, nil
+}
+
+// ListPage list records from Table with keyset pagination, the records are ordered by
+// the sort keys of options and then the primary columns.
+// The returned cursor is used as options.Cursor for listing next page, it's empty when no more records.
+// The columns for ordering should not be null.
+func (t *Table) ListPage(filter RowFilter, options ListOptions) ([]interface{}, string, error) {
+	return t.ListPageContext(context.Background(), filter, options)
+}
+
+// ListPageContext list records from Table with keyset pagination and context.
+func (t *Table) ListPageContext(ctx context.Context, filter RowFilter, options ListOptions) ([]interface{}, string, error) {
+	records := make([]interface{}, 0)
+	if options.Offset > 0 || options.Distinct {
+		return records, "", &ErrorSQLInvalid{Message: "offset or distinct is not supported in keyset pagination"}
+	}
+
+	terms, err := t.keysetTerms(options)
+	if err != nil {
+		return records, "", err
+	}
+
+	pageFilter := filter
+	if options.Cursor != "" {
+		values, err := t.decodeCursor(options.Cursor, terms)
+		if err != nil {
+			return records, "", err
+		}
+		pageFilter = RowFilterAnd{filter, keysetFilter{terms: terms, values: values}}
+	}
+
+	selectOptions := options
+	selectOptions.Cursor = ""
+	if options.Limit > 0 {
+		// 多查询一条用以判断是否有下一页
+		selectOptions.Limit = options.Limit + 1
+	}
+	selectOptions.OrderBy = terms
+	if !selectOptions.AllColumns && len(selectOptions.Columns) > 0 {
+		selectOptions.Columns = appendMissing(selectOptions.Columns, orderCols(terms))
+	}
+
+	// 分表时跨分表查询并归并
+	records, err = t.list(ctx, pageFilter, selectOptions)
+	if err != nil {
+		return records, "", err
+	}
+
+	if options.Limit <= 0 || len(records) <= int(options.Limit) {
+		return records, "", nil
+	}
+
+	records = records[:options.Limit]
+	cursor, err := encodeCursor(records[len(records)-1], terms)
+	return records, cursor, err
+}
+
+// keysetTerms return the sort keys in keyset pagination: the sort keys of options and then the primary columns,
+// the primary columns are in the direction of the last sort key of options.
+func (t *Table) keysetTerms(options ListOptions) ([]OrderTerm, error) {
+	pCols, err := t.getSchema().PrimaryCols()
+	if err != nil {
+		return nil, err
+	}
+	if len(pCols) == 0 {
+		return nil, &ErrorSQLInvalid{Message: "keyset pagination requires primary columns"}
+	}
+
+	terms := options.orderTerms()
+	desc := options.OrderDesc
+	for _, o := range terms {
+		if o.NullsFirst {
+			return nil, &ErrorSQLInvalid{Message: "nulls first ordering is not supported in keyset pagination"}
+		}
+		if !t.getSchema().hasCol(o.Col) {
+			return nil, &ErrorSQLInvalid{Message: fmt.Sprintf("unknown order column: %s", o.Col)}
+		}
+		desc = o.Desc
+	}
+
+	ret := append([]OrderTerm{}, terms...)
+	for _, c := range pCols {
+		if !containsString(orderCols(ret), c) {
+			ret = append(ret, OrderTerm{Col: c, Desc: desc})
+		}
+	}
+
+	return ret, nil
+}
+
+// decodeCursor decode values of cursor token in the types of row model fields.
+func (t *Table) decodeCursor(cursor string, terms []OrderTerm) ([]interface{}, error) {
+	errInvalid := &ErrorSQLInvalid{Message: "invalid cursor"}
+
+	bs, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		errInvalid.Err = err
+		return nil, errInvalid
+	}
+
+	var token cursorToken
+	if err := json.Unmarshal(bs, &token); err != nil {
+		errInvalid.Err = err
+		return nil, errInvalid
+	}
+	if !reflect.DeepEqual(token.Cols, cursorCols(terms)) || len(token.Values) != len(terms) {
+		errInvalid.Message = "cursor is not matched with the order columns"
+		return nil, errInvalid
+	}
+
+	record := t.RowModel()
+	if record == nil {
+		return nil, &ErrorSQLInvalid{Message: "row model not setted"}
+	}
+	fields := reflectx.NewMapper(DBSchemaTag).FieldMap(reflect.ValueOf(record))
+
+	values := make([]interface{}, 0, len(terms))
+	for i, k := range orderCols(terms) {
+		f, ok := fields[k]
+		if !ok {
+			return nil, fmt.Errorf("column %s not found in row model: %T", k, record)
+		}
+		if err := json.Unmarshal(token.Values[i], f.Addr().Interface()); err != nil {
+			errInvalid.Err = err
+			return nil, errInvalid
+		}
+		values = append(values, f.Interface())
+	}
+
+	return values, nil
+}
+
+// encodeCursor encode the column values of record as cursor token.
+func encodeCursor(record interface{}, terms []OrderTerm) (string, error) {
+	fields := reflectx.NewMapper(DBSchemaTag).FieldMap(reflect.ValueOf(record))
+
+	token := cursorToken{Cols: cursorCols(terms)}
+	for _, k := range orderCols(terms) {
+		f, ok := fields[k]
+		if !ok {
+			return "", fmt.Errorf("column %s not found in record: %T", k, record)
+		}
+
+		bs, err := json.Marshal(f.Interface())
+		if err != nil {
+			return "", err
+		}
+		token.Values = append(token.Values, bs)
+	}
+
+	bs, err := json.Marshal(&token)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bs), nil
+}
+
+// cursorCols return the columns stored in cursor token, the descending one is prefixed with `-`.
+func cursorCols(terms []OrderTerm) []string {
+	ret := make([]string, 0, len(terms))
+	for _, o := range terms {
+		if o.Desc {
+			ret = append(ret, "-"+o.Col)
+		} else {
+			ret = append(ret, o.Col)
+		}
+	}
+
+	return ret
+}
+
+// orderCols return the columns of sort keys.
+func orderCols(terms []OrderTerm) []string {
+	ret := make([]string, 0, len(terms))
+	for _, o := range terms {
+		ret = append(ret, o.Col)
+	}
+
+	return ret
+}
+
+// appendMissing append elements of others which not exist in list.
+func appendMissing(list []string, others []string) []string {
+	ret := append([]string{}, list...)
+	for _, o := range others {
+		if !containsString(ret, o) {
+			ret = append(ret, o)
+		}
+	}
+
+	return ret
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+
+	return false
+}
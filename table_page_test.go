@@ -0,0 +1,132 @@
+package sqlm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTable_ListPage(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_list_page", &testSimpleRecord{})
+	var records []interface{}
+	for i, score := range []int32{3, 1, 2, 3, 1, 2, 3} {
+		records = append(records, &testSimpleRecord{ID: int64(i + 1), Name: string(rune('a' + i)), Score: score})
+	}
+	if _, err := table.Inserts(records); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		options ListOptions
+		wantIDs []int64
+	}{
+		{"by primary", ListOptions{Limit: 3}, []int64{1, 2, 3, 4, 5, 6, 7}},
+		{"by score desc", ListOptions{Limit: 2, OrderByColumn: "score", OrderDesc: true}, []int64{7, 4, 1, 6, 3, 5, 2}},
+		{"by score with columns", ListOptions{Limit: 4, OrderByColumn: "score", Columns: []string{"name"}}, []int64{2, 5, 3, 6, 1, 4, 7}},
+		{
+			"by terms",
+			ListOptions{Limit: 3, OrderBy: []OrderTerm{{Col: "score", Desc: true}, {Col: "name"}}},
+			[]int64{1, 4, 7, 3, 6, 2, 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotIDs []int64
+			options := tt.options
+			for page := 0; ; page++ {
+				if page > len(records) {
+					t.Fatal("too many pages")
+				}
+
+				got, cursor, err := table.ListPage(nil, options)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(got) > int(options.Limit) {
+					t.Fatalf("Table.ListPage() got %d records, want <= %d", len(got), options.Limit)
+				}
+				for _, r := range got {
+					gotIDs = append(gotIDs, r.(*testSimpleRecord).ID)
+				}
+				if cursor == "" {
+					break
+				}
+				options.Cursor = cursor
+			}
+
+			if !reflect.DeepEqual(gotIDs, tt.wantIDs) {
+				t.Errorf("Table.ListPage() ids = %v, want %v", gotIDs, tt.wantIDs)
+			}
+		})
+	}
+
+	t.Run("resume by List", func(t *testing.T) {
+		_, cursor, err := table.ListPage(SelectorFilter{"score": 3}, ListOptions{Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := table.List(SelectorFilter{"score": 3}, ListOptions{Cursor: cursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0].(*testSimpleRecord).ID != 4 {
+			t.Errorf("Table.List() = %v, want records 4 and 7", got)
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, cursor, err := table.ListPage(nil, ListOptions{Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var errInvalid *ErrorSQLInvalid
+		for _, options := range []ListOptions{
+			{Limit: 1, Cursor: "not-a-cursor"},
+			{Limit: 1, Cursor: cursor, OrderByColumn: "score"},
+			{Limit: 1, OrderByColumn: "not_exist"},
+			{Limit: 1, OrderBy: []OrderTerm{{Col: "score", NullsFirst: true}}},
+			{Limit: 1, Offset: 1},
+		} {
+			if _, _, err := table.ListPage(nil, options); !errors.As(err, &errInvalid) {
+				t.Errorf("Table.ListPage(%+v) error = %v, want *ErrorSQLInvalid", options, err)
+			}
+		}
+	})
+}
+
+func TestTable_ListLimitOffset(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_list_limit_offset", &testSimpleRecord{})
+	for i := 1; i <= 5; i++ {
+		if _, err := table.Insert(&testSimpleRecord{ID: int64(i), Name: string(rune('a' + i))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		options ListOptions
+		wantIDs []int64
+	}{
+		{"limit", ListOptions{OrderByColumn: "id", Limit: 2}, []int64{1, 2}},
+		{"limit and offset", ListOptions{OrderByColumn: "id", Limit: 2, Offset: 2}, []int64{3, 4}},
+		{"offset only", ListOptions{OrderByColumn: "id", Offset: 3}, []int64{4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.List(nil, tt.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var gotIDs []int64
+			for _, r := range got {
+				gotIDs = append(gotIDs, r.(*testSimpleRecord).ID)
+			}
+			if !reflect.DeepEqual(gotIDs, tt.wantIDs) {
+				t.Errorf("Table.List() ids = %v, want %v", gotIDs, tt.wantIDs)
+			}
+		})
+	}
+}
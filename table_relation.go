@@ -0,0 +1,219 @@
+package sqlm
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// DBPreloadTag struct field tag for attaching the preloaded records of relation, like:
+//
+//	Rule  *Rule   `db:"-" preload:"rule"`
+//	Notes []*Note `db:"-" preload:"notes"`
+const DBPreloadTag = "preload"
+
+// Relation records in the related table mapped by column, the value of LocalCol in the table's record
+// equals to the value of RefCol in the related records.
+type Relation struct {
+	Name     string // name used in ListOptions.Preload and the `preload` tag of attached field.
+	Table    *Table // the related table.
+	LocalCol string // column of the table.
+	RefCol   string // column of the related table.
+	Many     bool   // has many related records, else has one.
+}
+
+// HasOne declare the relation that each record has one related record in table related,
+// the attached field should be a struct, pointer to struct or interface{}.
+func (t *Table) HasOne(name string, related *Table, localCol, refCol string) {
+	t.addRelation(Relation{Name: name, Table: related, LocalCol: localCol, RefCol: refCol})
+}
+
+// HasMany declare the relation that each record has many related records in table related,
+// the attached field should be a slice of struct, pointer to struct or interface{}.
+func (t *Table) HasMany(name string, related *Table, localCol, refCol string) {
+	t.addRelation(Relation{Name: name, Table: related, LocalCol: localCol, RefCol: refCol, Many: true})
+}
+
+func (t *Table) addRelation(r Relation) {
+	if t.relations == nil {
+		t.relations = make(map[string]Relation)
+	}
+	t.relations[r.Name] = r
+}
+
+// preloadRelations return the relations to preload of options.
+func (t *Table) preloadRelations(options ListOptions) ([]Relation, error) {
+	var ret []Relation
+	for _, name := range options.Preload {
+		r, ok := t.relations[name]
+		if !ok {
+			return nil, &ErrorSQLInvalid{Message: fmt.Sprintf("relation %s is not declared", name)}
+		}
+		if r.Table == nil || !t.getSchema().hasCol(r.LocalCol) || !r.Table.getSchema().hasCol(r.RefCol) {
+			return nil, &ErrorSQLInvalid{Message: fmt.Sprintf("relation %s has invalid table or columns", name)}
+		}
+		ret = append(ret, r)
+	}
+
+	return ret, nil
+}
+
+// preload query the related records with one `IN` query for each relation and attach them to records.
+func (t *Table) preload(ctx context.Context, records []interface{}, relations []Relation) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	mapper := reflectx.NewMapper(DBSchemaTag)
+	for _, r := range relations {
+		field, err := preloadField(reflect.TypeOf(records[0]), r.Name)
+		if err != nil {
+			return err
+		}
+
+		// 去重后的关联值
+		var values []interface{}
+		seen := make(map[interface{}]bool)
+		for _, record := range records {
+			key, ok := relationKey(mapper.FieldByName(reflect.ValueOf(record), r.LocalCol))
+			if ok && !seen[key] {
+				seen[key] = true
+				values = append(values, key)
+			}
+		}
+
+		relatedOf, err := t.relatedRecords(ctx, r, values)
+		if err != nil {
+			return fmt.Errorf("preload relation %s failed: %w", r.Name, err)
+		}
+
+		for _, record := range records {
+			key, _ := relationKey(mapper.FieldByName(reflect.ValueOf(record), r.LocalCol))
+			fv := reflect.Indirect(reflect.ValueOf(record)).FieldByIndex(field)
+			if err := attachRelated(fv, relatedOf[key], r.Many); err != nil {
+				return fmt.Errorf("preload relation %s failed: %w", r.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// relatedRecords query the related records grouped by the key of RefCol.
+func (t *Table) relatedRecords(ctx context.Context, r Relation, values []interface{}) (map[interface{}][]interface{}, error) {
+	ret := make(map[interface{}][]interface{})
+	if len(values) == 0 {
+		return ret, nil
+	}
+
+	related := r.Table
+	if t.tx != nil {
+		related = t.tx.Table(related)
+	}
+
+	// 超出方言的绑定变量限制时分批查询
+	chunkSize := len(values)
+	if dialect, err := related.dialect(); err == nil {
+		if d, ok := dialect.(BatchDialect); ok && d.MaxBindVars() > 0 && d.MaxBindVars() < chunkSize {
+			chunkSize = d.MaxBindVars()
+		}
+	}
+
+	mapper := reflectx.NewMapper(DBSchemaTag)
+	for start := 0; start < len(values); start += chunkSize {
+		end := start + chunkSize
+		if end > len(values) {
+			end = len(values)
+		}
+
+		filter := ColListFilter{Col: r.RefCol, Values: values[start:end]}
+		records, err := related.list(ctx, filter, ListOptions{AllColumns: true})
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if key, ok := relationKey(mapper.FieldByName(reflect.ValueOf(record), r.RefCol)); ok {
+				ret[key] = append(ret[key], record)
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// relationKey return the comparable value of column for mapping records, false when it's NULL.
+func relationKey(v reflect.Value) (interface{}, bool) {
+	if !v.IsValid() {
+		return nil, false
+	}
+
+	// 统一整数等类型, 如 int32 与 int64 的列可以关联
+	val, err := driver.DefaultParameterConverter.ConvertValue(v.Interface())
+	if err != nil || val == nil {
+		return nil, false
+	}
+	if b, ok := val.([]byte); ok {
+		return string(b), true
+	}
+
+	return val, true
+}
+
+// preloadField return the index of struct field tagged with `preload:"<name>"`.
+func preloadField(t reflect.Type, name string) ([]int, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct {
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.Tag.Get(DBPreloadTag) == name {
+				return f.Index, nil
+			}
+		}
+	}
+
+	return nil, &ErrorSQLInvalid{Message: fmt.Sprintf("no field tagged with %s:%q in %s", DBPreloadTag, name, t)}
+}
+
+// attachRelated set the related records to field, it's set to zero value when no related records.
+func attachRelated(field reflect.Value, related []interface{}, many bool) error {
+	if !many {
+		field.Set(reflect.Zero(field.Type()))
+		if len(related) == 0 {
+			return nil
+		}
+		return assignRelated(field, related[0])
+	}
+
+	if field.Kind() != reflect.Slice {
+		return fmt.Errorf("field for has many relation should be slice, got %s", field.Type())
+	}
+
+	slice := reflect.MakeSlice(field.Type(), len(related), len(related))
+	for i, record := range related {
+		if err := assignRelated(slice.Index(i), record); err != nil {
+			return err
+		}
+	}
+	field.Set(slice)
+
+	return nil
+}
+
+// assignRelated assign the related record which is pointer to struct usually, to struct or pointer.
+func assignRelated(dst reflect.Value, record interface{}) error {
+	v := reflect.ValueOf(record)
+	switch {
+	case v.Type().AssignableTo(dst.Type()):
+		dst.Set(v)
+	case v.Kind() == reflect.Ptr && v.Elem().Type().AssignableTo(dst.Type()):
+		dst.Set(v.Elem())
+	default:
+		return fmt.Errorf("can not assign %s to field of %s", v.Type(), dst.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package sqlm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type testRelRule struct {
+	ID   int64  `db:"id,type=INTEGER,primary"`
+	Name string `db:"name,type=VARCHAR(32)"`
+}
+
+type testRelNote struct {
+	ID      int64  `db:"id,type=INTEGER,primary"`
+	AlertID int32  `db:"alertId,type=INTEGER"`
+	Text    string `db:"text,type=VARCHAR(32)"`
+}
+
+type testRelAlert struct {
+	ID     int64          `db:"id,type=INTEGER,primary"`
+	RuleID sql.NullInt64  `db:"ruleId,type=INTEGER"`
+	Rule   *testRelRule   `db:"-" preload:"rule"`
+	Notes  []testRelNote  `db:"-" preload:"notes"`
+	Any    []interface{}  `preload:"any_notes"`
+	Bad    map[string]int `db:"-" preload:"bad"`
+}
+
+func newTestRelTables(t *testing.T) (alerts, rules, notes *Table) {
+	db := &Database{Driver: DriverSQLite3, DSN: fmt.Sprintf("file:%s/relation.db", t.TempDir())}
+	rules = &Table{Database: db, TableName: "test_rel_rules"}
+	rules.SetRowModel(func() interface{} { return &testRelRule{} })
+	notes = &Table{Database: db, TableName: "test_rel_notes"}
+	notes.SetRowModel(func() interface{} { return &testRelNote{} })
+	alerts = &Table{Database: db, TableName: "test_rel_alerts"}
+	alerts.SetRowModel(func() interface{} { return &testRelAlert{} })
+
+	alerts.HasOne("rule", rules, "ruleId", "id")
+	alerts.HasMany("notes", notes, "id", "alertId")
+	alerts.HasMany("any_notes", notes, "id", "alertId")
+	alerts.HasMany("bad", notes, "id", "alertId")
+
+	var records []interface{}
+	for _, r := range []*testRelRule{{1, "r1"}, {2, "r2"}} {
+		records = append(records, r)
+	}
+	testInsertRecords(t, rules, records)
+	testInsertRecords(t, notes, []interface{}{
+		&testRelNote{1, 1, "n1"}, &testRelNote{2, 1, "n2"}, &testRelNote{3, 2, "n3"},
+	})
+	testInsertRecords(t, alerts, []interface{}{
+		&testRelAlert{ID: 1, RuleID: sql.NullInt64{Int64: 1, Valid: true}},
+		&testRelAlert{ID: 2, RuleID: sql.NullInt64{Int64: 1, Valid: true}},
+		&testRelAlert{ID: 3},
+	})
+
+	return alerts, rules, notes
+}
+
+func testInsertRecords(t *testing.T, table *Table, records []interface{}) {
+	if err := table.Create(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.Inserts(records); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTable_List_preload(t *testing.T) {
+	alerts, _, _ := newTestRelTables(t)
+
+	rule1 := &testRelRule{1, "r1"}
+	tests := []struct {
+		name    string
+		options ListOptions
+		want    []*testRelAlert
+	}{
+		{
+			"has one and has many",
+			ListOptions{OrderByColumn: "id", Preload: []string{"rule", "notes"}},
+			[]*testRelAlert{
+				{ID: 1, RuleID: sql.NullInt64{Int64: 1, Valid: true}, Rule: rule1, Notes: []testRelNote{{1, 1, "n1"}, {2, 1, "n2"}}},
+				{ID: 2, RuleID: sql.NullInt64{Int64: 1, Valid: true}, Rule: rule1, Notes: []testRelNote{{3, 2, "n3"}}},
+				{ID: 3, Notes: []testRelNote{}},
+			},
+		},
+		{
+			"local column not selected",
+			ListOptions{Columns: []string{"id"}, OrderByColumn: "id", Limit: 1, Preload: []string{"rule"}},
+			[]*testRelAlert{{ID: 1, RuleID: sql.NullInt64{Int64: 1, Valid: true}, Rule: rule1}},
+		},
+		{
+			"interface slice",
+			ListOptions{OrderByColumn: "id", Limit: 1, Preload: []string{"any_notes"}},
+			[]*testRelAlert{{
+				ID: 1, RuleID: sql.NullInt64{Int64: 1, Valid: true},
+				Any: []interface{}{&testRelNote{1, 1, "n1"}, &testRelNote{2, 1, "n2"}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := alerts.List(nil, tt.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Table.List() got %d records, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i], tt.want[i]) {
+					t.Errorf("Table.List()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	t.Run("list page", func(t *testing.T) {
+		got, cursor, err := alerts.ListPage(nil, ListOptions{Limit: 2, Preload: []string{"rule"}})
+		if err != nil || cursor == "" || len(got) != 2 || got[1].(*testRelAlert).Rule == nil {
+			t.Errorf("Table.ListPage() = %+v, %q, %v", got, cursor, err)
+		}
+	})
+
+	t.Run("in transaction", func(t *testing.T) {
+		err := alerts.Database.WithTx(context.Background(), func(tx *Tx) error {
+			got, err := tx.Table(alerts).List(SelectorFilter{"id": 2}, ListOptions{Preload: []string{"notes"}})
+			if err != nil {
+				return err
+			}
+			if len(got) != 1 || len(got[0].(*testRelAlert).Notes) != 1 {
+				t.Errorf("Table.List() in transaction = %+v", got)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	var errInvalid *ErrorSQLInvalid
+	for _, name := range []string{"unknown", "bad"} {
+		t.Run("invalid "+name, func(t *testing.T) {
+			_, err := alerts.List(nil, ListOptions{Preload: []string{name}})
+			if err == nil || (name == "unknown" && !errors.As(err, &errInvalid)) {
+				t.Errorf("Table.List() error = %v, want error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package sqlm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ShardDDLOptions for Table#ExecShards() and Table#AlterShards()
+type ShardDDLOptions struct {
+	Concurrency int  // maximum shards altered at the same time, default is 1, it's ignored in transaction.
+	DryRun      bool // only return the planned statements without applying them.
+}
+
+// ShardDDLResult result of one shard table.
+type ShardDDLResult struct {
+	Table      string
+	Statements []string // planned statements, the ones after the failed statement are not applied.
+	Err        error
+}
+
+// ShardTables return the live tables of Table ordered by name: the table itself and the shards
+// created for the split column values, which are named as `<table>_<value1>[_<value2>...]`.
+// The live table with the name matched is treated as shard only when it has the split columns.
+func (t *Table) ShardTables() ([]string, error) {
+	return t.ShardTablesContext(context.Background())
+}
+
+// ShardTablesContext return the live tables of Table with context.
+func (t *Table) ShardTablesContext(ctx context.Context) ([]string, error) {
+	d, err := t.dialect()
+	if err != nil {
+		return nil, err
+	}
+	dialect, ok := d.(TableListDialect)
+	if !ok {
+		return nil, fmt.Errorf("dialect of driver %s does not support listing tables", t.Driver)
+	}
+	ext, err := t.executor()
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := dialect.TableNames(ctx, ext)
+	if err != nil {
+		return nil, fmt.Errorf("list tables failed: %w", err)
+	}
+
+	var ret []string
+	for _, name := range names {
+		if !t.isShardTable(name) {
+			continue
+		}
+		if name != t.TableName {
+			hasSplitCols, err := t.hasSplitCols(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			if !hasSplitCols {
+				continue
+			}
+		}
+		ret = append(ret, name)
+	}
+
+	return ret, nil
+}
+
+// hasSplitCols report whether the live table has all the split columns, it's true when the dialect
+// can not inspect the live columns.
+func (t *Table) hasSplitCols(ctx context.Context, table string) (bool, error) {
+	d, err := t.dialect()
+	if err != nil {
+		return false, err
+	}
+	inspector, ok := d.(InspectDialect)
+	if !ok {
+		return true, nil
+	}
+	ext, err := t.executor()
+	if err != nil {
+		return false, err
+	}
+
+	cols, err := inspector.TableColumns(ctx, ext, table)
+	if err != nil {
+		return false, fmt.Errorf("get columns of table %s failed: %w", table, err)
+	}
+	var names []string
+	for _, c := range cols {
+		names = append(names, c.Name)
+	}
+
+	return containsAll(names, t.getSchema().splitByColumns), nil
+}
+
+// isShardTable report whether the table is the table itself or one of its shards.
+func (t *Table) isShardTable(name string) bool {
+	if name == t.TableName {
+		return true
+	}
+
+	splitCols := len(t.getSchema().splitByColumns)
+	prefix := t.TableName + "_"
+	if splitCols == 0 || !strings.HasPrefix(name, prefix) {
+		return false
+	}
+
+	// the split values may contain `_`, so the suffix has one part at least for each split column.
+	suffix := strings.TrimPrefix(name, prefix)
+	return splitValueRegex.MatchString(suffix) && len(strings.Split(suffix, "_")) >= splitCols
+}
+
+// AlterShards apply the alter clause to all live tables of Table, such as: `ADD COLUMN c INT`.
+func (t *Table) AlterShards(alter string, options ShardDDLOptions) ([]ShardDDLResult, error) {
+	return t.AlterShardsContext(context.Background(), alter, options)
+}
+
+// AlterShardsContext apply the alter clause to all live tables of Table with context.
+func (t *Table) AlterShardsContext(ctx context.Context, alter string, options ShardDDLOptions) ([]ShardDDLResult, error) {
+	ddl := func(_ context.Context, table string) ([]string, error) {
+		return []string{fmt.Sprintf("ALTER TABLE %s %s", t.getSchema().quoteTable(table), alter)}, nil
+	}
+
+	return t.ExecShardsContext(ctx, ddl, options)
+}
+
+// ExecShards apply the statements returned by ddl to all live tables of Table.
+// The results are in the order of ShardTables(), the error is returned when any shard failed.
+func (t *Table) ExecShards(
+	ddl func(ctx context.Context, table string) ([]string, error), options ShardDDLOptions,
+) ([]ShardDDLResult, error) {
+	return t.ExecShardsContext(context.Background(), ddl, options)
+}
+
+// ExecShardsContext apply the statements returned by ddl to all live tables of Table with context.
+func (t *Table) ExecShardsContext(
+	ctx context.Context, ddl func(ctx context.Context, table string) ([]string, error), options ShardDDLOptions,
+) ([]ShardDDLResult, error) {
+	tables, err := t.ShardTablesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ext, err := t.ddlExecutor()
+	if err != nil {
+		return nil, err
+	}
+
+	// the transaction can not be used concurrently.
+	concurrency := options.Concurrency
+	if concurrency < 1 || t.tx != nil {
+		concurrency = 1
+	}
+
+	results := make([]ShardDDLResult, len(tables))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, table := range tables {
+		results[i].Table = table
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(result *ShardDDLResult) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			result.Statements, result.Err = ddl(ctx, result.Table)
+			if result.Err != nil || options.DryRun {
+				return
+			}
+			for _, statement := range result.Statements {
+				if _, err := ext.ExecContext(ctx, statement); err != nil {
+					result.Err = fmt.Errorf("%w\n sql: %s", err, statement)
+					return
+				}
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+
+	return results, shardDDLError(results)
+}
+
+// shardDDLError return error with the failed count and the first error.
+func shardDDLError(results []ShardDDLResult) error {
+	var failed []ShardDDLResult
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%d of %d shards failed, table %s: %w", len(failed), len(results), failed[0].Table, failed[0].Err)
+}
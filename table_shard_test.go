@@ -0,0 +1,127 @@
+package sqlm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testShardRecord struct {
+	ID    int64  `db:"id,type=INTEGER,auto_increment"`
+	Group string `db:"grp,type=VARCHAR(16),not_null,split"`
+	Name  string `db:"name,type=VARCHAR(32)"`
+}
+
+func newTestShardTable(t *testing.T) *Table {
+	table := newTestSQLiteTable(t, "test_shard", &testShardRecord{})
+
+	for _, group := range []string{"b", "a", "c_1"} {
+		if _, err := table.Insert(&testShardRecord{Group: group, Name: group}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// not shards.
+	other := &Table{Database: table.Database, TableName: "test_sharding"}
+	other.SetRowModel(func() interface{} { return &testShardRecord{} })
+	if err := other.Create(); err != nil {
+		t.Fatal(err)
+	}
+	if err := testExec(t, table.Database, "CREATE TABLE test_shard_log (id INTEGER, msg TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+
+	return table
+}
+
+func TestTable_ShardTables(t *testing.T) {
+	table := newTestShardTable(t)
+
+	got, err := table.ShardTables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"test_shard", "test_shard_a", "test_shard_b", "test_shard_c_1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Table.ShardTables() = %v, want %v", got, want)
+	}
+}
+
+func TestTable_AlterShards(t *testing.T) {
+	table := newTestShardTable(t)
+
+	results, err := table.AlterShards("ADD COLUMN score INT", ShardDDLOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 || results[1].Table != "test_shard_a" ||
+		!reflect.DeepEqual(results[1].Statements, []string{`ALTER TABLE "test_shard_a" ADD COLUMN score INT`}) {
+		t.Errorf("Table.AlterShards() dry run = %+v", results)
+	}
+	if err := testExec(t, table.Database, "SELECT score FROM test_shard_a"); err == nil {
+		t.Errorf("Table.AlterShards() should not apply statements in dry run")
+	}
+
+	results, err = table.AlterShards("ADD COLUMN score INT DEFAULT 0", ShardDDLOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("Table.AlterShards() result of %s error = %v", r.Table, r.Err)
+		}
+	}
+	for _, group := range []string{"a", "b", "c_1"} {
+		count, err := table.Count(SelectorFilter{"grp": group, "score": 0})
+		if err != nil || count != 1 {
+			t.Errorf("Table.Count() for group %s = %v, %v, want 1", group, count, err)
+		}
+	}
+
+	// the column already exists in shard b.
+	ddl := func(_ context.Context, table string) ([]string, error) {
+		if table == "test_shard_b" {
+			return []string{`ALTER TABLE "test_shard_b" ADD COLUMN score INT`}, nil
+		}
+		return []string{fmt.Sprintf(`ALTER TABLE "%s" ADD COLUMN level INT`, table)}, nil
+	}
+	results, err = table.ExecShards(ddl, ShardDDLOptions{Concurrency: 4})
+	if err == nil || !strings.Contains(err.Error(), "1 of 4 shards failed") {
+		t.Errorf("Table.ExecShards() error = %v, want one shard failed", err)
+	}
+	for _, r := range results {
+		if (r.Err != nil) != (r.Table == "test_shard_b") {
+			t.Errorf("Table.ExecShards() result of %s error = %v", r.Table, r.Err)
+		}
+	}
+}
+
+// testShardRecordV2 the version of testShardRecord with score column added.
+type testShardRecordV2 struct {
+	ID    int64  `db:"id,type=INTEGER,auto_increment"`
+	Group string `db:"grp,type=VARCHAR(16),not_null,split"`
+	Name  string `db:"name,type=VARCHAR(32)"`
+	Score int32  `db:"score,type=INT,default=0"`
+}
+
+func TestTable_Migrate_allShards(t *testing.T) {
+	old := newTestShardTable(t)
+	table := &Table{Database: old.Database, TableName: old.TableName}
+	table.SetRowModel(func() interface{} { return &testShardRecordV2{} })
+
+	plan, err := table.Migrate(MigrateOptions{AllShards: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`ALTER TABLE "test_shard" ADD COLUMN "score" INT DEFAULT 0`,
+		`ALTER TABLE "test_shard_a" ADD COLUMN "score" INT DEFAULT 0`,
+		`ALTER TABLE "test_shard_b" ADD COLUMN "score" INT DEFAULT 0`,
+		`ALTER TABLE "test_shard_c_1" ADD COLUMN "score" INT DEFAULT 0`,
+	}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("Table.Migrate() = %q, want %q", plan, want)
+	}
+}
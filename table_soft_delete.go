@@ -0,0 +1,56 @@
+package sqlm
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Restore the soft deleted records matched the filter in Table.
+func (t *Table) Restore(filter RowFilter) error {
+	return t.RestoreContext(context.Background(), filter)
+}
+
+// RestoreContext restore the soft deleted records in Table with context.
+func (t *Table) RestoreContext(ctx context.Context, filter RowFilter) error {
+	col := t.getSchema().SoftDeleteCol()
+	if col == "" {
+		return &ErrorSQLInvalid{Message: fmt.Sprintf("table %s is not soft deleted", t.TableName)}
+	}
+	if err := t.checkDeleteFilter(filter); err != nil {
+		return err
+	}
+
+	deleted := t.getSchema().deletedFilter(filter, DeletedOnly)
+	_, err := t.update(ctx, deleted, []string{col}, map[string]interface{}{col: nil})
+	return err
+}
+
+// softDeleteRows set the deleted time of records not deleted yet.
+func (t *Table) softDeleteRows(ctx context.Context, filter RowFilter) error {
+	if err := t.checkDeleteFilter(filter); err != nil {
+		return err
+	}
+
+	col := t.getSchema().SoftDeleteCol()
+	notDeleted := t.getSchema().deletedFilter(filter, DeletedExcluded)
+	_, err := t.update(ctx, notDeleted, []string{col}, map[string]interface{}{col: time.Now()})
+	return err
+}
+
+// checkDeleteFilter 与物理删除一致, 不允许不带where的删除或恢复.
+func (t *Table) checkDeleteFilter(filter RowFilter) error {
+	if filter == nil {
+		return &ErrorSQLInvalid{Message: "不允许不带where的删除操作"}
+	}
+
+	where, err := t.wherePattern(filter)
+	if err != nil {
+		return &ErrorSQLInvalid{"where条件组装失败", err}
+	}
+	if where == nil || where.Format == "" {
+		return &ErrorSQLInvalid{Message: "不允许不带where的删除操作"}
+	}
+
+	return nil
+}
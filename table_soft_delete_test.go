@@ -0,0 +1,126 @@
+package sqlm
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testSoftRecord struct {
+	ID        int64      `db:"id,type=INTEGER,primary"`
+	Name      string     `db:"name,type=VARCHAR(32)"`
+	DeletedAt *time.Time `db:"deleted_at,type=DATETIME,soft_delete"`
+}
+
+func newTestSoftTable(t *testing.T) *Table {
+	table := newTestSQLiteTable(t, "test_soft", &testSoftRecord{})
+	testInsertRecords(t, table, []interface{}{
+		&testSoftRecord{ID: 1, Name: "a"}, &testSoftRecord{ID: 2, Name: "b"}, &testSoftRecord{ID: 3, Name: "c"},
+	})
+
+	return table
+}
+
+func TestTableSchema_SelectSQL_softDelete(t *testing.T) {
+	s := newTestTableSchema(DriverSQLite3, "test_soft", testSoftRecord{})
+	filter := RowFilterOr{SelectorFilter{"id": 1}, SelectorFilter{"name": "b"}}
+	tests := []struct {
+		name    string
+		options ListOptions
+		want    string
+	}{
+		{"excluded", ListOptions{}, `"deleted_at" IS NULL AND ((("id"=:id) OR ("name"=:name)))`},
+		{"included", ListOptions{}.WithDeleted(), `(("id"=:id) OR ("name"=:name))`},
+		{"only", ListOptions{}.OnlyDeleted(), `"deleted_at" IS NOT NULL AND ((("id"=:id) OR ("name"=:name)))`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _, err := s.SelectSQL(filter, tt.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if query.Where != tt.want {
+				t.Errorf("TableSchema.SelectSQL() where = %s, want %s", query.Where, tt.want)
+			}
+		})
+	}
+
+	if got := s.InsertCols(); len(got) != 2 {
+		t.Errorf("TableSchema.InsertCols() = %v, soft delete column should not be inserted", got)
+	}
+}
+
+func TestTable_softDelete(t *testing.T) {
+	table := newTestSoftTable(t)
+
+	if err := table.Delete(SelectorFilter{"id": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if count, err := table.Count(nil); err != nil || count != 2 {
+		t.Errorf("Table.Count() = %v, %v, want 2", count, err)
+	}
+	if err := table.Get(SelectorFilter{"id": 1}, &testSoftRecord{}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Table.Get() error = %v, want %v", err, sql.ErrNoRows)
+	}
+	if dup, err := table.IsDup(&testSoftRecord{ID: 1}); err != nil || dup != nil {
+		t.Errorf("Table.IsDup() = %v, %v, want nil", dup, err)
+	}
+
+	all, err := table.List(nil, ListOptions{}.WithDeleted())
+	if err != nil || len(all) != 3 {
+		t.Errorf("Table.List() with deleted = %v, %v, want 3 records", all, err)
+	}
+	deleted, err := table.List(nil, ListOptions{}.OnlyDeleted())
+	if err != nil || len(deleted) != 1 || deleted[0].(*testSoftRecord).DeletedAt == nil {
+		t.Errorf("Table.List() only deleted = %v, %v, want record 1 with deleted time", deleted, err)
+	}
+
+	if err := table.Restore(SelectorFilter{"id": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := table.Count(nil); err != nil || count != 3 {
+		t.Errorf("Table.Count() after restore = %v, %v, want 3", count, err)
+	}
+
+	if err := table.HardDelete(SelectorFilter{"id": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if all, err := table.List(nil, ListOptions{}.WithDeleted()); err != nil || len(all) != 2 {
+		t.Errorf("Table.List() after hard delete = %v, %v, want 2 records", all, err)
+	}
+
+	var errInvalid *ErrorSQLInvalid
+	if err := table.Delete(nil); !errors.As(err, &errInvalid) {
+		t.Errorf("Table.Delete() without filter error = %v, want ErrorSQLInvalid", err)
+	}
+
+	plain := &Table{Database: table.Database, TableName: "test_soft"}
+	plain.SetRowModel(func() interface{} { return &testRuleRecord{} })
+	if err := plain.Restore(SelectorFilter{"id": 1}); !errors.As(err, &errInvalid) {
+		t.Errorf("Table.Restore() on table without soft delete column error = %v, want ErrorSQLInvalid", err)
+	}
+}
+
+func TestTable_softDelete_notRevived(t *testing.T) {
+	table := newTestSoftTable(t)
+	if err := table.Delete(SelectorFilter{"id": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.Save(&testSoftRecord{ID: 1, Name: "saved"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.Upsert(&testSoftRecord{ID: 1, Name: "upserted"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.Get(SelectorFilter{"id": 1}, &testSoftRecord{}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Table.Get() error = %v, want %v", err, sql.ErrNoRows)
+	}
+	deleted, err := table.List(nil, ListOptions{}.OnlyDeleted())
+	if err != nil || len(deleted) != 1 || deleted[0].(*testSoftRecord).DeletedAt == nil {
+		t.Errorf("Table.List() only deleted = %v, %v, want record 1 kept deleted", deleted, err)
+	}
+}
@@ -1,8 +1,10 @@
 package sqlm
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/ahmetb/go-linq"
@@ -151,7 +153,7 @@ func TestTable_Create(t *testing.T) {
 					Driver: "mysql",
 					DSN:    fmt.Sprintf("user:pass@tcp(%s)/fake", fakeServer.Listener.Addr()),
 				},
-				TableName: "test_table$$$",
+				TableName: "not_exist.test.table",
 			},
 			wantErr: true,
 		},
@@ -167,3 +169,341 @@ func TestTable_Create(t *testing.T) {
 		})
 	}
 }
+
+// newTestSQLiteTable create the table in a new sqlite database, model is pointer to the row struct.
+func newTestSQLiteTable(t *testing.T, name string, model interface{}) *Table {
+	t.Helper()
+
+	table := &Table{
+		Database: &Database{
+			Driver: DriverSQLite3,
+			DSN:    fmt.Sprintf("file:%s/%s.db", t.TempDir(), name),
+		},
+		TableName: name,
+	}
+	modelType := reflect.TypeOf(model).Elem()
+	table.SetRowModel(func() interface{} { return reflect.New(modelType).Interface() })
+
+	if err := table.Create(); err != nil {
+		t.Fatal(err)
+	}
+
+	return table
+}
+
+func TestTable_Context(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_context", &testSimpleRecord{})
+
+	var hookCtxValues []interface{}
+	type ctxKey struct{}
+	table.TableHooks.Insert.Before = append(table.TableHooks.Insert.Before,
+		InsertContextHookFunc(func(ctx context.Context, _ *Table, _ interface{}) error {
+			hookCtxValues = append(hookCtxValues, ctx.Value(ctxKey{}))
+			return nil
+		}),
+		InsertHookFunc(func(_ *Table, _ interface{}) error {
+			hookCtxValues = append(hookCtxValues, "legacy")
+			return nil
+		}),
+	)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "v")
+	if _, err := table.InsertContext(ctx, &testSimpleRecord{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{"v", "legacy"}; !reflect.DeepEqual(hookCtxValues, want) {
+		t.Errorf("hook context values = %v, want %v", hookCtxValues, want)
+	}
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := table.ListContext(canceledCtx, nil, ListOptions{}); err == nil {
+		t.Errorf("Table.ListContext() with canceled context, want error")
+	}
+	if _, err := table.InsertContext(canceledCtx, &testSimpleRecord{Name: "b"}); err == nil {
+		t.Errorf("Table.InsertContext() with canceled context, want error")
+	}
+
+	records, err := table.ListContext(ctx, nil, ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Errorf("Table.ListContext() got %d records, want %d", len(records), 1)
+	}
+}
+
+func TestTable_keywordIdentifiers(t *testing.T) {
+	table := newTestSQLiteTable(t, "order", &testKeywordRecord{})
+	if _, err := table.Inserts([]interface{}{
+		&testKeywordRecord{ID: 1, Order: 2, Key: "a", Group: "g1"},
+		&testKeywordRecord{ID: 2, Order: 1, Key: "b", Group: "g1"},
+		&testKeywordRecord{ID: 3, Order: 3, Key: "c", Group: "g2"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.Save(&testKeywordRecord{ID: 3, Order: 0, Key: "c", Group: "g1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Update(SelectorFilter{"key": "a"}, map[string]interface{}{"group": "g2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Delete(LikeFilter{Key: "key", Value: "b%"}); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := table.List(SelectorFilter{"group": "g1"}, ListOptions{Columns: []string{"id", "order", "key"}, OrderByColumn: "order"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].(*testKeywordRecord).Key != "c" {
+		t.Errorf("Table.List() = %v, want only record with key c", records)
+	}
+
+	var got testKeywordRecord
+	if err := table.Get(SelectorFilter{"key": "a"}, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Group != "g2" {
+		t.Errorf("Table.Get() group = %v, want %v", got.Group, "g2")
+	}
+
+	dup, err := table.IsDup(&testKeywordRecord{ID: got.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dup == nil {
+		t.Errorf("Table.IsDup() = nil, want record")
+	}
+}
+
+func TestTable_parameterizedFilters(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_parameterized", &testSimpleRecord{})
+	if _, err := table.Inserts([]interface{}{
+		&testSimpleRecord{ID: 1, Name: "O'Brien"},
+		&testSimpleRecord{ID: 2, Name: "a' OR '1'='1"},
+		&testSimpleRecord{ID: 3, Name: "c"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the set column is same with the filter column.
+	if err := table.Update(SelectorFilter{"name": "O'Brien"}, map[string]interface{}{"name": "O'Neil", "score": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Update(ColListFilter{Col: "name", Values: []interface{}{"O'Neil", "c"}}, map[string]interface{}{"score": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Delete(ColListFilter{Col: "name", Values: []interface{}{"a' OR '1'='1"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := table.List(IDListFilter{1, 2, 3}, ListOptions{OrderByColumn: "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{
+		&testSimpleRecord{ID: 1, Name: "O'Neil", Score: 2},
+		&testSimpleRecord{ID: 3, Name: "c", Score: 2},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("Table.List() = %v, want %v", records, want)
+	}
+}
+
+func TestTable_filterAlgebra(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_filter_algebra", &testSimpleRecord{})
+	if _, err := table.Inserts([]interface{}{
+		&testSimpleRecord{ID: 1, Name: "a", Score: 1},
+		&testSimpleRecord{ID: 2, Name: "b", Score: 2},
+		&testSimpleRecord{ID: 3, Name: "c", Score: 3},
+		&testSimpleRecord{ID: 4, Name: "d", Score: 4},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	filter := RowFilterAnd{
+		RowFilterOr{
+			CompareFilter{Col: "score", Op: OpLe, Value: 2},
+			CompareFilter{Col: "score", Op: OpGt, Value: 3},
+		},
+		NotFilter{Filter: InFilter{Col: "name", Values: []interface{}{"a"}}},
+		NotInFilter{Col: "name", Values: []interface{}{"d"}},
+		NullFilter{Col: "name", NotNull: true},
+	}
+	records, err := table.List(filter, ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{&testSimpleRecord{ID: 2, Name: "b", Score: 2}}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("Table.List() = %v, want %v", records, want)
+	}
+}
+
+func TestTable_unsupportedHook(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_unsupported_hook", &testSimpleRecord{})
+	table.TableHooks.Delete.Before = []interface{}{"not a hook"}
+
+	if err := table.Delete(SelectorFilter{"name": "a"}); err == nil {
+		t.Errorf("Table.Delete() with unsupported hook, want error")
+	}
+}
+
+func TestTableNotExistErrorRegex(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want bool
+	}{
+		{"mysql", "Error 1146: Table 'fake.test' doesn't exist", true},
+		{"postgres", `pq: relation "test_1" does not exist`, true},
+		{"other", "Error 1062: Duplicate entry '1' for key 'PRIMARY'", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := regexp.MustCompile(TableNotExistErrorRegex).MatchString(tt.msg); got != tt.want {
+				t.Errorf("TableNotExistErrorRegex match %q = %v, want %v", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTable_CountExists(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_count_exists", &testSimpleRecord{})
+	var records []interface{}
+	for i, score := range []int32{3, 1, 2, 3} {
+		records = append(records, &testSimpleRecord{ID: int64(i + 1), Name: string(rune('a' + i)), Score: score})
+	}
+	if _, err := table.Inserts(records); err != nil {
+		t.Fatal(err)
+	}
+
+	notExistTable := &Table{Database: table.Database, TableName: "test_count_exists_not_exist"}
+	notExistTable.SetRowModel(func() interface{} { return &testSimpleRecord{} })
+
+	tests := []struct {
+		name      string
+		table     *Table
+		filter    RowFilter
+		wantCount int64
+	}{
+		{"all", table, nil, 4},
+		{"selector", table, SelectorFilter{"score": 3}, 2},
+		{"in list", table, InFilter{Col: "id", Values: []interface{}{1, 2, 9}}, 2},
+		{"none", table, CompareFilter{Col: "score", Op: OpGt, Value: 3}, 0},
+		{"table not exist", notExistTable, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := tt.table.Count(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != tt.wantCount {
+				t.Errorf("Table.Count() = %v, want %v", count, tt.wantCount)
+			}
+
+			exist, err := tt.table.Exists(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exist != (tt.wantCount > 0) {
+				t.Errorf("Table.Exists() = %v, want %v", exist, tt.wantCount > 0)
+			}
+		})
+	}
+}
+
+func TestTable_Upsert(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_upsert", &testSimpleRecord{})
+
+	var hookCalls []string
+	table.TableHooks.Upsert.Before = []interface{}{
+		UpsertHookFunc(func(_ *Table, _ interface{}) error {
+			hookCalls = append(hookCalls, "upsert.before")
+			return nil
+		}),
+	}
+	table.TableHooks.Upserts.After = []interface{}{
+		UpsertsContextHookFunc(func(_ context.Context, _ *Table, records []interface{}) error {
+			hookCalls = append(hookCalls, fmt.Sprintf("upserts.after:%d", len(records)))
+			return nil
+		}),
+	}
+
+	// the auto increment id is not inserted, records conflict by the unique name.
+	if _, err := table.Upsert(&testSimpleRecord{Name: "a", Score: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.Upsert(&testSimpleRecord{Name: "a", Score: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.Upserts([]interface{}{
+		&testSimpleRecord{Name: "a", Score: 3},
+		&testSimpleRecord{Name: "b", Score: 4},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := table.List(nil, ListOptions{OrderByColumn: "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{
+		&testSimpleRecord{ID: 1, Name: "a", Score: 3},
+		&testSimpleRecord{ID: 2, Name: "b", Score: 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Table.List() after upserting = %v, want %v", got, want)
+	}
+
+	wantHookCalls := []string{"upsert.before", "upsert.before", "upserts.after:2"}
+	if !reflect.DeepEqual(hookCalls, wantHookCalls) {
+		t.Errorf("upsert hooks calls = %v, want %v", hookCalls, wantHookCalls)
+	}
+
+	// 主键插入时, 与其他记录的唯一键冲突
+	keyed := &Table{Database: table.Database, TableName: "test_upsert_keyed"}
+	keyed.SetRowModel(func() interface{} { return &testUpsertKeyedRecord{} })
+	if err := keyed.Create(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keyed.Upserts([]interface{}{
+		&testUpsertKeyedRecord{ID: 1, Name: "a"}, &testUpsertKeyedRecord{ID: 1, Name: "b"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keyed.Upsert(&testUpsertKeyedRecord{ID: 2, Name: "b"}); err == nil {
+		t.Error("Table.Upsert() should fail when conflicted with unique key of other record")
+	}
+}
+
+// testUpsertKeyedRecord record with explicit primary key and unique column.
+type testUpsertKeyedRecord struct {
+	ID   int64  `db:"id,type=INTEGER,primary"`
+	Name string `db:"name,type=VARCHAR(32),unique"`
+}
+
+func TestTable_Upsert_zeroIDs(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_upsert_zero", &testSimpleRecord{})
+
+	for _, name := range []string{"a", "b", "c"} {
+		if _, err := table.Upsert(&testSimpleRecord{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := table.List(nil, ListOptions{OrderByColumn: "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{
+		&testSimpleRecord{ID: 1, Name: "a"}, &testSimpleRecord{ID: 2, Name: "b"}, &testSimpleRecord{ID: 3, Name: "c"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Table.List() after upserting = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package sqlm
+
+import (
+	"database/sql"
+	"reflect"
+
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// initVersion set the version of record to 1 when it's zero before inserting,
+// it's ignored when the record is not addressable, such as struct value.
+func (t *Table) initVersion(record interface{}) {
+	field := t.versionField(record)
+	if field.IsValid() && field.IsZero() {
+		setVersion(field, 1)
+	}
+}
+
+// checkVersionSaved return ErrStaleRecord when no record saved, or increase the version of record
+// to be consistent with the saved one.
+func (t *Table) checkVersionSaved(ret sql.Result, targetTable string, record interface{}) error {
+	affected, err := ret.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	col := t.getSchema().VersionCol()
+	field := reflectx.NewMapper(DBSchemaTag).FieldByName(reflect.ValueOf(record), col)
+	if affected == 0 {
+		var version interface{}
+		if field.IsValid() {
+			version = field.Interface()
+		}
+		return &ErrStaleRecord{Table: targetTable, Version: version}
+	}
+
+	if field = t.versionField(record); field.IsValid() {
+		setVersion(field, versionOf(field)+1)
+	}
+
+	return nil
+}
+
+// versionField return the settable integer field of version column, invalid value when absent.
+func (t *Table) versionField(record interface{}) reflect.Value {
+	col := t.getSchema().VersionCol()
+	v := reflect.ValueOf(record)
+	if col == "" || v.Kind() != reflect.Ptr || v.IsNil() {
+		return reflect.Value{}
+	}
+
+	field := reflectx.NewMapper(DBSchemaTag).FieldByName(v, col)
+	if !field.IsValid() || !field.CanSet() {
+		return reflect.Value{}
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return field
+	default:
+		return reflect.Value{}
+	}
+}
+
+func versionOf(field reflect.Value) int64 {
+	if field.Kind() >= reflect.Uint && field.Kind() <= reflect.Uint64 {
+		return int64(field.Uint())
+	}
+
+	return field.Int()
+}
+
+func setVersion(field reflect.Value, version int64) {
+	if field.Kind() >= reflect.Uint && field.Kind() <= reflect.Uint64 {
+		field.SetUint(uint64(version))
+		return
+	}
+
+	field.SetInt(version)
+}
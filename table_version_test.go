@@ -0,0 +1,70 @@
+package sqlm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testVersionRecord struct {
+	ID      int64  `db:"id,type=INTEGER,primary"`
+	Name    string `db:"name,type=VARCHAR(32)"`
+	Version uint32 `db:"version,type=INTEGER,not_null,version"`
+}
+
+func TestTableSchema_UpdateColsWhenDup_version(t *testing.T) {
+	s := newTestTableSchema(DriverSQLite3, "test_version", testVersionRecord{})
+	if got, want := s.UpdateColsWhenDup(), []string{"name"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TableSchema.UpdateColsWhenDup() = %v, want %v", got, want)
+	}
+	if got := s.VersionCol(); got != "version" {
+		t.Errorf("TableSchema.VersionCol() = %v, want version", got)
+	}
+}
+
+func TestTable_Save_version(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_version", &testVersionRecord{})
+
+	record := &testVersionRecord{ID: 1, Name: "a"}
+	testInsertRecords(t, table, []interface{}{record})
+	if record.Version != 1 {
+		t.Errorf("Table.Insert() version = %d, want 1", record.Version)
+	}
+
+	var first, second testVersionRecord
+	for _, r := range []*testVersionRecord{&first, &second} {
+		if err := table.Get(SelectorFilter{"id": 1}, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first.Name = "b"
+	if err := table.Save(&first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Version != 2 {
+		t.Errorf("Table.Save() version = %d, want 2", first.Version)
+	}
+
+	second.Name = "c"
+	var errStale *ErrStaleRecord
+	if err := table.Save(&second); !errors.As(err, &errStale) || errStale.Version != uint32(1) {
+		t.Errorf("Table.Save() stale record error = %v, want ErrStaleRecord of version 1", err)
+	}
+	if err := table.Save(&testVersionRecord{ID: 2, Version: 1}); !errors.As(err, &errStale) {
+		t.Errorf("Table.Save() absent record error = %v, want ErrStaleRecord", err)
+	}
+
+	// the version is increased instead of overwritten by upsert.
+	if _, err := table.Upsert(&testVersionRecord{ID: 1, Name: "d", Version: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got testVersionRecord
+	if err := table.Get(SelectorFilter{"id": 1}, &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := (testVersionRecord{ID: 1, Name: "d", Version: 3}); got != want {
+		t.Errorf("Table.Get() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,133 @@
+package sqlm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Tx sql transaction, tables bound to it run their operations in the transaction.
+type Tx struct {
+	db  *Database
+	tx  *sqlx.Tx
+	seq *int // savepoint sequence shared by nested transactions.
+}
+
+// WithTx run fn in a transaction.
+// Transaction will be committed when fn returned nil, and rolled back when fn returned error or panicked.
+// MySQL commits the transaction implicitly on DDL, so tables auto created in it are created out of the transaction.
+func (p *Database) WithTx(ctx context.Context, fn func(tx *Tx) error) error {
+	con, err := p.Con()
+	if err != nil {
+		return err
+	}
+
+	sqlxTx, err := con.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+
+	var seq int
+	tx := &Tx{db: p, tx: sqlxTx, seq: &seq}
+
+	return tx.run(fn, sqlxTx.Commit, sqlxTx.Rollback)
+}
+
+// WithTx run fn in a nested transaction implemented by savepoint.
+// Only the changes in fn will be rolled back when fn returned error or panicked.
+func (tx *Tx) WithTx(ctx context.Context, fn func(tx *Tx) error) error {
+	*tx.seq++
+	savepoint := fmt.Sprintf("sqlm_sp_%d", *tx.seq)
+
+	if _, err := tx.tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return fmt.Errorf("create savepoint failed: %w", err)
+	}
+
+	release := func() error {
+		_, err := tx.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint)
+		return err
+	}
+	rollback := func() error {
+		_, err := tx.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint)
+		return err
+	}
+
+	return tx.run(fn, release, rollback)
+}
+
+// Table return a copy of the table which operations are bound to the transaction.
+// The table should belong to the same database as the transaction.
+func (tx *Tx) Table(t *Table) *Table {
+	return &Table{
+		Database:          t.Database,
+		TableName:         t.TableName,
+		TableHooks:        t.TableHooks,
+		InsertBatchSize:   t.InsertBatchSize,
+		FanOutConcurrency: t.FanOutConcurrency,
+		rowModeler:        t.rowModeler,
+		tx:                tx,
+		relations:         t.relations,
+	}
+}
+
+// Tx return the underlying sqlx transaction.
+func (tx *Tx) Tx() *sqlx.Tx {
+	return tx.tx
+}
+
+// guard run fn in a savepoint, the transaction is rolled back to the savepoint when fn failed.
+func (tx *Tx) guard(ctx context.Context, fn func() error) error {
+	end, err := tx.savepoint(ctx)
+	if err != nil {
+		return err
+	}
+
+	return end(fn())
+}
+
+// savepoint create a savepoint and return the func to end it with the result of the guarded statements:
+// the savepoint is released when err is nil, otherwise the transaction is rolled back to it before releasing.
+// The err is returned by end, wrapped when ending failed.
+func (tx *Tx) savepoint(ctx context.Context) (end func(err error) error, err error) {
+	*tx.seq++
+	savepoint := fmt.Sprintf("sqlm_sp_%d", *tx.seq)
+
+	if _, err := tx.tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return nil, fmt.Errorf("create savepoint failed: %w", err)
+	}
+
+	return func(err error) error {
+		if err != nil {
+			if _, rollbackErr := tx.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); rollbackErr != nil {
+				return fmt.Errorf("%w\nrollback to savepoint failed: %v", err, rollbackErr)
+			}
+		}
+		if _, releaseErr := tx.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint); releaseErr != nil {
+			if err != nil {
+				return fmt.Errorf("%w\nrelease savepoint failed: %v", err, releaseErr)
+			}
+			return fmt.Errorf("release savepoint failed: %w", releaseErr)
+		}
+
+		return err
+	}, nil
+}
+
+func (tx *Tx) run(fn func(tx *Tx) error, commit, rollback func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			_ = rollback()
+			panic(r)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		if rollbackErr := rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w\nrollback failed: %v", err, rollbackErr)
+		}
+		return err
+	}
+
+	return commit()
+}
@@ -0,0 +1,157 @@
+package sqlm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestDatabase_WithTx(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_tx", &testSimpleRecord{})
+	ctx := context.Background()
+	errMock := errors.New("mock error")
+
+	count := func() int {
+		records, err := table.List(nil, ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(records)
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		err := table.Database.WithTx(ctx, func(tx *Tx) error {
+			_, err := tx.Table(table).Insert(&testSimpleRecord{Name: "commit"})
+			return err
+		})
+		if err != nil {
+			t.Errorf("Database.WithTx() error = %v, wantErr %v", err, false)
+		}
+		if got := count(); got != 1 {
+			t.Errorf("records count = %d, want %d", got, 1)
+		}
+	})
+
+	t.Run("rollback when error", func(t *testing.T) {
+		err := table.Database.WithTx(ctx, func(tx *Tx) error {
+			if _, err := tx.Table(table).Insert(&testSimpleRecord{Name: "rollback"}); err != nil {
+				return err
+			}
+			return errMock
+		})
+		if !errors.Is(err, errMock) {
+			t.Errorf("Database.WithTx() error = %v, want %v", err, errMock)
+		}
+		if got := count(); got != 1 {
+			t.Errorf("records count = %d, want %d", got, 1)
+		}
+	})
+
+	t.Run("rollback when panic", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("Database.WithTx() should re-panic")
+			}
+			if got := count(); got != 1 {
+				t.Errorf("records count = %d, want %d", got, 1)
+			}
+		}()
+
+		_ = table.Database.WithTx(ctx, func(tx *Tx) error {
+			if _, err := tx.Table(table).Insert(&testSimpleRecord{Name: "panic"}); err != nil {
+				return err
+			}
+			panic("mock panic")
+		})
+	})
+
+	t.Run("nested savepoint", func(t *testing.T) {
+		err := table.Database.WithTx(ctx, func(tx *Tx) error {
+			if _, err := tx.Table(table).Insert(&testSimpleRecord{Name: "outer"}); err != nil {
+				return err
+			}
+
+			nestedErr := tx.WithTx(ctx, func(nested *Tx) error {
+				if _, err := nested.Table(table).Insert(&testSimpleRecord{Name: "inner"}); err != nil {
+					return err
+				}
+				return errMock
+			})
+			if !errors.Is(nestedErr, errMock) {
+				t.Errorf("Tx.WithTx() error = %v, want %v", nestedErr, errMock)
+			}
+
+			return tx.WithTx(ctx, func(nested *Tx) error {
+				_, err := nested.Table(table).Insert(&testSimpleRecord{Name: "inner2"})
+				return err
+			})
+		})
+		if err != nil {
+			t.Errorf("Database.WithTx() error = %v, wantErr %v", err, false)
+		}
+		if got := count(); got != 3 {
+			t.Errorf("records count = %d, want %d", got, 3)
+		}
+	})
+}
+
+func TestTx_Table(t *testing.T) {
+	table := newTestSQLiteTable(t, "test_tx_table", &testSimpleRecord{})
+	table.InsertBatchSize = 1
+	table.FanOutConcurrency = 2
+
+	err := table.Database.WithTx(context.Background(), func(tx *Tx) error {
+		got := tx.Table(table)
+		if got.InsertBatchSize != table.InsertBatchSize || got.FanOutConcurrency != table.FanOutConcurrency ||
+			got.TableName != table.TableName {
+			t.Errorf("Tx.Table() = %+v, want the options of %+v", got, table)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// abortingDialect dialect for unit testing, the statements are guarded by savepoints as in postgresql.
+type abortingDialect struct {
+	Dialect
+}
+
+func (abortingDialect) ErrorAbortsTx() bool {
+	return true
+}
+
+func TestTx_savepointReleased(t *testing.T) {
+	replaceTestDialect(t, DriverSQLite3, func(d Dialect) Dialect { return abortingDialect{d} })
+	table := newTestSQLiteTable(t, "test_tx_savepoint", &testSimpleRecord{})
+	missing := &Table{Database: table.Database, TableName: "test_tx_missing"}
+	missing.SetRowModel(func() interface{} { return &testSimpleRecord{} })
+
+	err := table.Database.WithTx(context.Background(), func(tx *Tx) error {
+		txTable := tx.Table(table)
+		if _, err := txTable.Insert(&testSimpleRecord{Name: "a"}); err != nil {
+			return err
+		}
+		if _, err := txTable.List(nil, ListOptions{}); err != nil {
+			return err
+		}
+		err := txTable.Iterate(nil, ListOptions{}, func(interface{}) error { return ErrStopIterate })
+		if err != nil {
+			return err
+		}
+		if err := tx.Table(missing).Get(SelectorFilter{"id": 1}, &testSimpleRecord{}); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Table.Get() on missing table error = %v, want %v", err, sql.ErrNoRows)
+		}
+
+		// all the savepoints are released, no one remains in transaction.
+		if _, err := tx.Tx().Exec("RELEASE SAVEPOINT sqlm_sp_1"); err == nil {
+			t.Errorf("savepoint sqlm_sp_1 should be released")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}